@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+)
+
+// errCheckFailed is returned by checkOrder when
+// the times are out of order.
+var errCheckFailed = errors.New("times out of order")
+
+// checkOrder reads times from r, one per line, and reports
+// to w each line whose time is earlier than the time
+// on the most recent line with a time, allowing times
+// to go backwards by up to skew. If strict is true,
+// a time equal to the previous time (less skew) is
+// also reported. It returns errCheckFailed if any
+// regressions or unparseable lines were found.
+//
+// If pat is non-nil, the first time matching pat on each line is
+// used and lines without a time are ignored.
+func checkOrder(
+	r io.Reader,
+	w io.Writer,
+	pat *regexp.Regexp,
+	parseTime parseFunc,
+	formatTime formatFunc,
+	strict bool,
+	skew time.Duration,
+) error {
+	var prev time.Time
+	prevLine := 0
+	failed := false
+	lineNum := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		var t time.Time
+		if pat != nil {
+			var ok bool
+			if t, ok = findTime(line, pat, parseTime); !ok {
+				continue
+			}
+		} else {
+			var err error
+			t, _, err = parseTime(line)
+			if err != nil {
+				fmt.Fprintf(w, "line %d: parse error on %q: %v\n", lineNum, line, err)
+				failed = true
+				continue
+			}
+		}
+		if prevLine > 0 {
+			back := prev.Sub(t)
+			if back > skew || strict && back == skew {
+				s, err := formatTime(t, "")
+				if err != nil {
+					return err
+				}
+				ps, err := formatTime(prev, "")
+				if err != nil {
+					return err
+				}
+				if back == 0 {
+					fmt.Fprintf(w, "line %d: %s is the same as on line %d\n", lineNum, s, prevLine)
+				} else {
+					fmt.Fprintf(w, "line %d: %s is %v before %s on line %d\n", lineNum, s, back, ps, prevLine)
+				}
+				failed = true
+			}
+		}
+		prev, prevLine = t, lineNum
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// runCheck runs the check subcommand, which checks that
+// the times in the -f file are in order. The arguments
+// hold any flags after the "check" argument.
+func runCheck(args []string) {
+	if err := flag.CommandLine.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	applyConfig()
+	if flag.NArg() > 0 {
+		fatalf("unexpected arguments to check")
+	}
+	if *file == "" {
+		fatalf("check requires the -f flag")
+	}
+	if len(columns) > 0 || len(jsonPaths) > 0 {
+		fatalf("check does not support -col or -json")
+	}
+	if *skew < 0 {
+		fatalf("-skew must not be negative")
+	}
+	formatTime, err := formatter()
+	if err != nil {
+		fatalf("%v", err)
+	}
+	parseTime, err := timeParser(*inFormat, *tzIn)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	var pat *regexp.Regexp
+	if *replace {
+		pat, err = timePattern(*inFormat)
+		if err != nil {
+			fatalf("%v", err)
+		}
+	}
+	f, err := openFile(*file)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	err = checkOrder(f, os.Stdout, pat, parseTime, formatTime, *strict, *skew)
+	if err == errCheckFailed {
+		os.Exit(1)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
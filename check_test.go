@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// errReader returns the data in r followed by err.
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	if err == io.EOF {
+		err = r.err
+	}
+	return n, err
+}
+
+func formatUTC(t time.Time, _ string) (string, error) {
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+func TestCheckOrder(t *testing.T) {
+	c := qt.New(t)
+	p, err := newParser("unix")
+	c.Assert(err, qt.IsNil)
+	var out bytes.Buffer
+	err = checkOrder(strings.NewReader("1700000000\n1700000002\n1700000001\n"), &out, nil, p.Parse, formatUTC, false, 0)
+	c.Assert(err, qt.Equals, errCheckFailed)
+	c.Assert(out.String(), qt.Equals, "line 3: 2023-11-14T22:13:21Z is 1s before 2023-11-14T22:13:22Z on line 2\n")
+
+	out.Reset()
+	err = checkOrder(strings.NewReader("1700000000\n1700000002\n1700000001\n"), &out, nil, p.Parse, formatUTC, false, time.Second)
+	c.Assert(err, qt.IsNil)
+	c.Assert(out.String(), qt.Equals, "")
+}
+
+func TestCheckOrderReadError(t *testing.T) {
+	c := qt.New(t)
+	p, err := newParser("unix")
+	c.Assert(err, qt.IsNil)
+	r := &errReader{strings.NewReader("1700000000\n"), fmt.Errorf("read failed")}
+	err = checkOrder(r, ioutil.Discard, nil, p.Parse, formatUTC, false, 0)
+	c.Assert(err, qt.ErrorMatches, "read failed")
+	// A line too long for the scanner is an error
+	// rather than the end of the input.
+	long := strings.Repeat("x", 100000)
+	err = checkOrder(strings.NewReader("1700000000\n"+long+"\n1\n"), ioutil.Discard, nil, p.Parse, formatUTC, false, 0)
+	c.Assert(err, qt.ErrorMatches, ".*token too long")
+}
+
+func TestConvertFileReadError(t *testing.T) {
+	c := qt.New(t)
+	p, err := newParser("unix")
+	c.Assert(err, qt.IsNil)
+	r := &errReader{strings.NewReader("1700000000\n"), fmt.Errorf("read failed")}
+	var out bytes.Buffer
+	err = convertFile(r, &out, p.Parse, formatUTC, &window{})
+	c.Assert(err, qt.ErrorMatches, "read failed")
+	c.Assert(out.String(), qt.Equals, "2023-11-14T22:13:20Z\n")
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// column holds a column selected for conversion.
+type column struct {
+	// name holds the header name of the column, if it was
+	// selected by name.
+	name string
+	// index holds the zero-based index of the column.
+	index int
+	// parseTime parses times in the column.
+	parseTime parseFunc
+}
+
+// parseColumn parses a column specification of the form
+// column[=format][@zone], where column is a 1-based column
+// number or a header name.
+func parseColumn(spec string) (*column, error) {
+	sel, parseTime, err := parseSelector(spec)
+	if err != nil {
+		return nil, err
+	}
+	c := &column{
+		parseTime: parseTime,
+	}
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("bad column number in %q", spec)
+		}
+		c.index = n - 1
+	} else {
+		c.name = sel
+	}
+	return c, nil
+}
+
+// parseSelector parses a specification of the form
+// selector[=format][@zone] and returns the selector and
+// a function to parse times in the given format and zone,
+// which default to those specified by the -i and -itz flags.
+func parseSelector(spec string) (string, parseFunc, error) {
+	sel, format, zone := splitSelector(spec)
+	if sel == "" {
+		return "", nil, fmt.Errorf("empty selector in %q", spec)
+	}
+	parseTime, err := timeParser(format, zone)
+	if err != nil {
+		return "", nil, fmt.Errorf("bad selector %q: %v", spec, err)
+	}
+	return sel, parseTime, nil
+}
+
+// splitSelector splits a specification of the form
+// selector[=format][@zone] into its parts, using
+// the -i and -itz flags for the format and zone
+// when they're not specified. A backslash in the
+// selector makes the character after it part of the
+// selector, so that it can contain = and @. Similarly,
+// the zone follows the last @ in the format that isn't
+// escaped by a backslash, which is left for the layout.
+func splitSelector(spec string) (sel, format, zone string) {
+	format, zone = *inFormat, *tzIn
+	var buf strings.Builder
+	i := 0
+	for ; i < len(spec); i++ {
+		c := spec[i]
+		if c == '=' || c == '@' {
+			break
+		}
+		if c == '\\' && i+1 < len(spec) {
+			i++
+			c = spec[i]
+		}
+		buf.WriteByte(c)
+	}
+	sel, rest := buf.String(), spec[i:]
+	if strings.HasPrefix(rest, "=") {
+		format, rest = rest[1:], ""
+		if j := lastUnescaped(format, '@'); j >= 0 {
+			format, rest = format[:j], format[j:]
+		}
+	}
+	if strings.HasPrefix(rest, "@") {
+		zone = rest[1:]
+	}
+	return sel, format, zone
+}
+
+// lastUnescaped returns the index of the last instance of c
+// in s that isn't escaped by a backslash, or -1 if there is none.
+func lastUnescaped(s string, c byte) int {
+	last := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			last = i
+		}
+	}
+	return last
+}
+
+// convertColumns reads delimited records from r and writes them to w with
+// the times in the columns selected by specs reformatted by formatTime.
+// Only records with a time in the first selected column inside
+// the window are written.
+func convertColumns(r io.Reader, w io.Writer, specs []string, formatTime formatFunc, win *window) error {
+	comma, err := delimiter(*delim)
+	if err != nil {
+		return err
+	}
+	cols := make([]*column, len(specs))
+	byName := false
+	for i, spec := range specs {
+		c, err := parseColumn(spec)
+		if err != nil {
+			return err
+		}
+		cols[i] = c
+		byName = byName || c.name != ""
+	}
+	if byName && !*header {
+		return fmt.Errorf("cannot select columns by name without -header")
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	defer cw.Flush()
+	if *header {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, c := range cols {
+			if c.name == "" {
+				continue
+			}
+			c.index = -1
+			for i, name := range rec {
+				if name == c.name {
+					c.index = i
+					break
+				}
+			}
+			if c.index == -1 {
+				return fmt.Errorf("column %q not found in header", c.name)
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		out := rec
+		if !*orig {
+			out = append([]string(nil), rec...)
+		}
+		var first time.Time
+		found := false
+		for i, c := range cols {
+			if c.index >= len(rec) || rec[c.index] == "" {
+				continue
+			}
+			val := rec[c.index]
+			t, tf, err := c.parseTime(val)
+			if err != nil {
+				line, _ := cr.FieldPos(c.index)
+				fmt.Fprintf(os.Stderr, "line %d: parse error on %q: %v\n", line, val, err)
+				continue
+			}
+			if i == 0 {
+				first, found = t, true
+			}
+			if *orig {
+				continue
+			}
+			s, err := formatTime(t, tf)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "cannot format %q: %v\n", val, err)
+				continue
+			}
+			out[c.index] = s
+		}
+		if !win.include(first, found) {
+			continue
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func delimiter(s string) (rune, error) {
+	switch s {
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if len([]rune(s)) != 1 {
+		return 0, fmt.Errorf("delimiter %q must be a single character", s)
+	}
+	return []rune(s)[0], nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var splitSelectorTests = []struct {
+	spec         string
+	expectSel    string
+	expectFormat string
+	expectZone   string
+}{{
+	spec:         "3",
+	expectSel:    "3",
+	expectFormat: "any",
+	expectZone:   "UTC",
+}, {
+	spec:         "created=unixmilli",
+	expectSel:    "created",
+	expectFormat: "unixmilli",
+	expectZone:   "UTC",
+}, {
+	spec:         "3=rfc1123@Europe/Paris",
+	expectSel:    "3",
+	expectFormat: "rfc1123",
+	expectZone:   "Europe/Paris",
+}, {
+	spec:         "when@tokyo",
+	expectSel:    "when",
+	expectFormat: "any",
+	expectZone:   "tokyo",
+}, {
+	spec:         "when=2006-01-02 15:04=5",
+	expectSel:    "when",
+	expectFormat: "2006-01-02 15:04=5",
+	expectZone:   "UTC",
+}, {
+	spec:         `a\=b=unix@UTC`,
+	expectSel:    "a=b",
+	expectFormat: "unix",
+	expectZone:   "UTC",
+}, {
+	spec:         `user\@host`,
+	expectSel:    "user@host",
+	expectFormat: "any",
+	expectZone:   "UTC",
+}, {
+	spec:         `a\\b`,
+	expectSel:    `a\b`,
+	expectFormat: "any",
+	expectZone:   "UTC",
+}, {
+	spec:         `.spans[].start=2006-01-02\@15:04@Asia/Tokyo`,
+	expectSel:    ".spans[].start",
+	expectFormat: `2006-01-02\@15:04`,
+	expectZone:   "Asia/Tokyo",
+}}
+
+func TestSplitSelector(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(inFormat, "any")
+	c.Patch(tzIn, "UTC")
+	for _, test := range splitSelectorTests {
+		c.Run(test.spec, func(c *qt.C) {
+			sel, format, zone := splitSelector(test.spec)
+			c.Check(sel, qt.Equals, test.expectSel)
+			c.Check(format, qt.Equals, test.expectFormat)
+			c.Check(zone, qt.Equals, test.expectZone)
+		})
+	}
+}
+
+var convertColumnsTests = []struct {
+	testName    string
+	specs       []string
+	header      bool
+	delim       string
+	input       string
+	expect      string
+	expectError string
+}{{
+	testName: "number",
+	specs:    []string{"2=unix"},
+	input:    "a,1700000000,b\nc,1700000060,d\n",
+	expect:   "a,2023-11-14T22:13:20Z,b\nc,2023-11-14T22:14:20Z,d\n",
+}, {
+	testName: "quoted-fields",
+	specs:    []string{"1=unix"},
+	input:    "1700000000,\"x, \"\"y\"\"\"\n\"1700000060\",z\n",
+	expect:   "2023-11-14T22:13:20Z,\"x, \"\"y\"\"\"\n2023-11-14T22:14:20Z,z\n",
+}, {
+	testName: "header",
+	specs:    []string{"created=unixmilli"},
+	header:   true,
+	input:    "id,created\n1,1700000000000\n2,\n",
+	expect:   "id,created\n1,2023-11-14T22:13:20Z\n2,\n",
+}, {
+	testName: "header-name-with-equals-and-at",
+	specs:    []string{`a\=b=unix`, `user\@host=unix@Asia/Tokyo`},
+	header:   true,
+	input:    "a=b,user@host\n1700000000,1700000000\n",
+	expect:   "a=b,user@host\n2023-11-14T22:13:20Z,2023-11-15T07:13:20+09:00\n",
+}, {
+	testName: "tab",
+	specs:    []string{"2=unix"},
+	delim:    "tab",
+	input:    "a,b\t1700000000\n",
+	expect:   "a,b\t2023-11-14T22:13:20Z\n",
+}, {
+	testName:    "name-without-header",
+	specs:       []string{"created"},
+	input:       "1\n",
+	expectError: `cannot select columns by name without -header`,
+}, {
+	testName:    "name-not-in-header",
+	specs:       []string{"updated"},
+	header:      true,
+	input:       "id,created\n",
+	expectError: `column "updated" not found in header`,
+}, {
+	testName:    "bad-column-number",
+	specs:       []string{"0"},
+	input:       "1\n",
+	expectError: `bad column number in "0"`,
+}, {
+	testName:    "bad-delimiter",
+	specs:       []string{"1"},
+	delim:       "::",
+	input:       "1\n",
+	expectError: `delimiter "::" must be a single character`,
+}}
+
+func TestConvertColumns(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(inFormat, "any")
+	c.Patch(tzIn, "UTC")
+	formatTime, err := formatterFor("rfc3339", "")
+	c.Assert(err, qt.IsNil)
+	for _, test := range convertColumnsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(header, test.header)
+			if test.delim != "" {
+				c.Patch(delim, test.delim)
+			}
+			var buf bytes.Buffer
+			err := convertColumns(strings.NewReader(test.input), &buf, test.specs, formatTime, &window{keep: true})
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rogpeppe/godate/timeformat"
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// The shell completion scripts call "godate __complete" with the words
+// of the command line after the command name, the last of which is the
+// word being completed, and offer the words it prints, one per line.
+// The printed words all start with the word being completed, except
+// that time zones can also be completed from the last element of their
+// names, so "tok" completes to "Asia/Tokyo". When nothing is printed,
+// the shells complete file names.
+
+const bashCompletion = `# bash completion for godate.
+# Add this line to ~/.bashrc:
+#	source <(godate completion bash)
+
+_godate() {
+	local line=${COMP_LINE:0:COMP_POINT}
+	local -a words
+	read -ra words <<<"$line"
+	if [[ $line == *[[:space:]] ]]; then
+		words+=("")
+	fi
+	# Bash splits words at colons, equals and at signs, so remove
+	# the part of the word before them from the completions.
+	local word=${words[${#words[@]}-1]}
+	local pre=${word%"${word##*[:=@]}"}
+	local IFS=$'\n'
+	COMPREPLY=($("${words[0]}" __complete "${words[@]:1}" 2>/dev/null))
+	COMPREPLY=("${COMPREPLY[@]#"$pre"}")
+	if [[ ${#COMPREPLY[@]} == 1 && ${COMPREPLY[0]} == *: ]]; then
+		compopt -o nospace
+	fi
+}
+complete -o default -F _godate godate
+`
+
+const zshCompletion = `#compdef godate
+# zsh completion for godate.
+# Add this line to ~/.zshrc (after compinit):
+#	source <(godate completion zsh)
+
+_godate() {
+	local -a cands
+	cands=("${(@f)$(${words[1]} __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	cands=(${cands:#})
+	if (( ${#cands} == 0 )); then
+		_files
+		return
+	fi
+	compadd -U -Q -- $cands
+}
+compdef _godate godate
+`
+
+const fishCompletion = `# fish completion for godate.
+# Add this line to ~/.config/fish/config.fish:
+#	godate completion fish | source
+
+function __godate_complete
+	set -l tokens (commandline -opc)
+	set -l current (commandline -ct)
+	set -l cands ($tokens[1] __complete $tokens[2..-1] "$current" 2>/dev/null)
+	if test (count $cands) -eq 0
+		__fish_complete_path "$current"
+		return
+	end
+	printf '%s\n' $cands
+end
+complete -c godate -f -a '(__godate_complete)'
+`
+
+// completionScripts holds the completion script for each shell.
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+// runCompletion runs the completion subcommand, which
+// prints the completion script for the shell named in args.
+func runCompletion(args []string) {
+	if len(args) != 1 {
+		fatalf("usage: godate completion bash|zsh|fish")
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		fatalf("unknown shell %q (known shells are bash, zsh and fish)", args[0])
+	}
+	fmt.Print(script)
+}
+
+// runComplete runs the hidden __complete subcommand used by the
+// completion scripts, which prints the completions of the last
+// of args, one per line.
+func runComplete(args []string) {
+	for _, cand := range completeArgs(args) {
+		fmt.Println(cand)
+	}
+}
+
+// subcommands holds the subcommands that
+// can be given as the first argument.
+var subcommands = []string{"tz", "check", "merge", "serve", "completion"}
+
+// completeArgs returns the completions of the last of args,
+// which hold the command line arguments up to the cursor.
+func completeArgs(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	word := args[len(args)-1]
+	// Find the positional arguments before the word, and
+	// the flag it's the value of, if any.
+	var positional []string
+	valueOf := ""
+	for i := 0; i < len(args)-1; i++ {
+		arg := args[i]
+		// Flags are only parsed before the first argument
+		// or after a subcommand that takes flags.
+		if len(positional) == 0 || len(positional) == 1 && hasFlags(positional[0]) {
+			if name, ok := flagName(arg); ok {
+				if f := flag.Lookup(name); f != nil && !isBoolFlag(f) && !strings.Contains(arg, "=") {
+					if i == len(args)-2 {
+						valueOf = name
+					}
+					i++
+				}
+				continue
+			}
+		}
+		positional = append(positional, arg)
+	}
+	var cands []string
+	switch {
+	case valueOf != "":
+		cands = completeFlagValue(valueOf, word)
+	case strings.HasPrefix(word, "-") && !isDeltaStart(word):
+		name, _ := flagName(word)
+		if i := strings.Index(name, "="); i >= 0 {
+			// The value of a flag in -name=value form.
+			prefix := word[:len(word)-len(name)+i+1]
+			for _, cand := range completeFlagValue(name[:i], name[i+1:]) {
+				cands = append(cands, prefix+cand)
+			}
+			break
+		}
+		var names []string
+		flag.VisitAll(func(f *flag.Flag) {
+			names = append(names, f.Name)
+		})
+		cands = withPrefix(names, "-", word)
+	case len(positional) == 0:
+		cands = append(withPrefix(subcommands, "", word), completeTimeArg(word)...)
+	case positional[0] == "tz":
+		cands = completeZone(word)
+	case positional[0] == "completion":
+		if len(positional) == 1 {
+			cands = withPrefix([]string{"bash", "fish", "zsh"}, "", word)
+		}
+	case hasFlags(positional[0]):
+		// File names, or nothing.
+	default:
+		cands = completeTimeArg(word)
+	}
+	sort.Strings(cands)
+	return cands
+}
+
+// hasFlags reports whether the given
+// subcommand accepts flags after it.
+func hasFlags(subcommand string) bool {
+	return subcommand == "check" || subcommand == "merge" || subcommand == "serve"
+}
+
+// flagName returns the name of the flag in arg, without the
+// leading dashes, and reports whether arg is a flag.
+func flagName(arg string) (string, bool) {
+	if len(arg) < 2 || arg[0] != '-' || isDeltaStart(arg) {
+		return "", false
+	}
+	return strings.TrimPrefix(arg[1:], "-"), true
+}
+
+// isDeltaStart reports whether s looks like the start of a
+// negative delta rather than a flag.
+func isDeltaStart(s string) bool {
+	return len(s) > 1 && s[0] == '-' && (s[1] == '.' || '0' <= s[1] && s[1] <= '9')
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// completeFlagValue returns the completions of word
+// as the value of the named flag.
+func completeFlagValue(name, word string) []string {
+	switch name {
+	case "itz", "otz":
+		return completeZone(word)
+	case "i", "o":
+		var formats []string
+		for format := range timeparse.Formats() {
+			formats = append(formats, format)
+		}
+		for format := range userFormats {
+			formats = append(formats, format)
+		}
+		if name == "o" {
+			formats = append(formats, "json")
+		}
+		return withPrefix(formats, "", word)
+	case "ical", "ocal":
+		return withPrefix(timeformat.CalendarNames(), "", word)
+	case "locale":
+		return withPrefix(timeformat.LocaleNames(), "", word)
+	case "serial":
+		return withPrefix(timeparse.SerialSystems(), "", word)
+	case "ixdtf":
+		return withPrefix([]string{"reject", "use", "ignore", "prefer"}, "", word)
+	case "fiscal":
+		var months []string
+		for _, m := range timeformat.English.ShortMonths {
+			months = append(months, strings.ToLower(m))
+		}
+		return withPrefix(months, "", word)
+	case "hist":
+		return withPrefix([]string{"weekday", "1h", "1d", "1w", "1mo"}, "", word)
+	}
+	if f := flag.Lookup(name); f != nil && isBoolFlag(f) {
+		return withPrefix([]string{"true", "false"}, "", word)
+	}
+	return nil
+}
+
+// deltaUnits holds the units that can be used in deltas.
+var deltaUnits = []string{
+	"y", "year", "years",
+	"q", "quarter", "quarters",
+	"mo", "month", "months",
+	"w", "week", "weeks",
+	"d", "day", "days",
+	"h", "m", "s", "ms", "us", "ns",
+}
+
+// truncationUnits holds the calendar units that can be used in truncations.
+var truncationUnits = []string{
+	"y", "year",
+	"fy", "fiscalyear",
+	"q", "quarter",
+	"mo", "month",
+	"w", "week",
+	"d", "day",
+	"h", "m", "s",
+}
+
+// completeTimeArg returns the completions of word as
+// a time, delta, truncation or @zone argument.
+func completeTimeArg(word string) []string {
+	switch {
+	case strings.Contains(word, "@"):
+		i := strings.LastIndex(word, "@") + 1
+		var cands []string
+		for _, zone := range completeZone(word[i:]) {
+			cands = append(cands, word[:i]+zone)
+		}
+		return cands
+	case strings.HasPrefix(word, "trunc:"):
+		return withPrefix(truncationUnits, "trunc:", word)
+	case word != "" && (word[0] == '+' || word[0] == '-'):
+		if i := strings.LastIndexAny(word, "0123456789.") + 1; i > 0 {
+			return withPrefix(deltaUnits, word[:i], word)
+		}
+		return nil
+	}
+	return withPrefix([]string{"now", "trunc:"}, "", word)
+}
+
+// withPrefix returns prefix+name for each name such that
+// the result starts with word, ignoring case.
+func withPrefix(names []string, prefix, word string) []string {
+	var cands []string
+	word = strings.ToLower(word)
+	for _, name := range names {
+		if s := prefix + name; strings.HasPrefix(strings.ToLower(s), word) {
+			cands = append(cands, s)
+		}
+	}
+	return cands
+}
+
+// completeZone returns the time zones and aliases whose names,
+// or the last elements of whose names, start with word,
+// ignoring case.
+func completeZone(word string) []string {
+	word = strings.ToLower(word)
+	var cands []string
+	for _, zone := range timeparse.MatchZones("") {
+		lzone := strings.ToLower(zone)
+		if strings.HasPrefix(lzone, word) || strings.HasPrefix(lzone[strings.LastIndex(lzone, "/")+1:], word) {
+			cands = append(cands, zone)
+		}
+	}
+	cands = append(cands, withPrefix(zoneAliases(), "", word)...)
+	cands = append(cands, withPrefix([]string{"Local"}, "", word)...)
+	return cands
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var completeArgsTests = []struct {
+	testName string
+	args     []string
+	expect   []string
+}{{
+	testName: "first-word",
+	args:     []string{""},
+	expect:   []string{"check", "completion", "merge", "now", "serve", "trunc:", "tz"},
+}, {
+	testName: "subcommand",
+	args:     []string{"c"},
+	expect:   []string{"check", "completion"},
+}, {
+	testName: "subcommand-after-flag",
+	args:     []string{"-u", "ch"},
+	expect:   []string{"check"},
+}, {
+	testName: "completion-shell",
+	args:     []string{"completion", ""},
+	expect:   []string{"bash", "fish", "zsh"},
+}, {
+	testName: "completion-done",
+	args:     []string{"completion", "bash", ""},
+	expect:   nil,
+}, {
+	testName: "flag-name",
+	args:     []string{"-ot"},
+	expect:   []string{"-otz"},
+}, {
+	testName: "check-flag",
+	args:     []string{"check", "-str"},
+	expect:   []string{"-strict"},
+}, {
+	testName: "merge-flag-after-file",
+	args:     []string{"merge", "a.log", "-pr"},
+	expect:   []string{"-prefix"},
+}, {
+	testName: "zone-flag",
+	args:     []string{"-otz", "tok"},
+	expect:   []string{"Asia/Tokyo"},
+}, {
+	testName: "zone-flag-with-equals",
+	args:     []string{"-otz=tok"},
+	expect:   []string{"-otz=Asia/Tokyo"},
+}, {
+	testName: "zone-flag-double-dash",
+	args:     []string{"--otz=tok"},
+	expect:   []string{"--otz=Asia/Tokyo"},
+}, {
+	testName: "local-zone",
+	args:     []string{"-itz", "loc"},
+	expect:   []string{"Local"},
+}, {
+	testName: "format-flag",
+	args:     []string{"-i", "unixmi"},
+	expect:   []string{"unixmicro", "unixmilli"},
+}, {
+	testName: "json-output-only",
+	args:     []string{"-i", "js"},
+	expect:   nil,
+}, {
+	testName: "bool-flag-value",
+	args:     []string{"-jsonnum=t"},
+	expect:   []string{"-jsonnum=true"},
+}, {
+	testName: "bool-flag-takes-no-argument",
+	args:     []string{"-abs", "n"},
+	expect:   []string{"now"},
+}, {
+	testName: "after-flag-value",
+	args:     []string{"-f", "x", "n"},
+	expect:   []string{"now"},
+}, {
+	testName: "delta-units",
+	args:     []string{"now", "+2m"},
+	expect:   []string{"+2m", "+2mo", "+2month", "+2months", "+2ms"},
+}, {
+	testName: "negative-delta-units",
+	args:     []string{"now", "-1w"},
+	expect:   []string{"-1w", "-1week", "-1weeks"},
+}, {
+	testName: "fractional-delta-units",
+	args:     []string{"now", "+1.5h"},
+	expect:   []string{"+1.5h"},
+}, {
+	testName: "truncation",
+	args:     []string{"now", "trunc:f"},
+	expect:   []string{"trunc:fiscalyear", "trunc:fy"},
+}, {
+	testName: "at-zone",
+	args:     []string{"now", "@tok"},
+	expect:   []string{"@Asia/Tokyo"},
+}, {
+	testName: "tz-last-element",
+	args:     []string{"tz", "samo"},
+	expect:   []string{"Pacific/Samoa", "US/Samoa"},
+}, {
+	testName: "tz-full-name",
+	args:     []string{"tz", "asia/tok"},
+	expect:   []string{"Asia/Tokyo"},
+}}
+
+func TestCompleteArgs(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range completeArgsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Assert(completeArgs(test.args), qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+var completeFlagValueTests = []struct {
+	testName string
+	flag     string
+	word     string
+	expect   []string
+}{{
+	testName: "zone",
+	flag:     "otz",
+	word:     "tok",
+	expect:   []string{"Asia/Tokyo"},
+}, {
+	testName: "zone-ignores-case",
+	flag:     "itz",
+	word:     "TOKYO",
+	expect:   []string{"Asia/Tokyo"},
+}, {
+	testName: "zone-alias",
+	flag:     "otz",
+	word:     "of",
+	expect:   []string{"office"},
+}, {
+	testName: "output-format",
+	flag:     "o",
+	word:     "js",
+	expect:   []string{"json"},
+}, {
+	testName: "input-format",
+	flag:     "i",
+	word:     "js",
+	expect:   nil,
+}, {
+	testName: "user-format",
+	flag:     "i",
+	word:     "sys",
+	expect:   []string{"syslog"},
+}, {
+	testName: "calendar",
+	flag:     "ical",
+	word:     "he",
+	expect:   []string{"hebrew"},
+}, {
+	testName: "locale",
+	flag:     "locale",
+	word:     "d",
+	expect:   []string{"de"},
+}, {
+	testName: "serial",
+	flag:     "serial",
+	word:     "ex",
+	expect:   []string{"excel", "excel1904"},
+}, {
+	testName: "ixdtf",
+	flag:     "ixdtf",
+	word:     "p",
+	expect:   []string{"prefer"},
+}, {
+	testName: "fiscal",
+	flag:     "fiscal",
+	word:     "o",
+	expect:   []string{"oct"},
+}, {
+	testName: "hist",
+	flag:     "hist",
+	word:     "w",
+	expect:   []string{"weekday"},
+}, {
+	testName: "bool",
+	flag:     "strict",
+	word:     "",
+	expect:   []string{"false", "true"},
+}, {
+	testName: "free-form",
+	flag:     "f",
+	word:     "x",
+	expect:   nil,
+}}
+
+func TestCompleteFlagValue(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(&userConfig, config{
+		Zones: map[string]string{
+			"office": "Europe/London",
+		},
+	})
+	c.Patch(&userFormats, map[string]string{
+		"syslog": "Jan _2 15:04:05",
+	})
+	for _, test := range completeFlagValueTests {
+		c.Run(test.testName, func(c *qt.C) {
+			got := completeFlagValue(test.flag, test.word)
+			sort.Strings(got)
+			c.Assert(got, qt.DeepEquals, test.expect)
+		})
+	}
+}
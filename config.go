@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// config holds the contents of the user's configuration file.
+type config struct {
+	// Formats maps format names to layouts. The names
+	// can be used wherever the built-in names can.
+	Formats map[string]string `json:"formats"`
+
+	// ITZ and OTZ hold the default time zones for the
+	// -itz and -otz flags.
+	ITZ string `json:"itz"`
+	OTZ string `json:"otz"`
+
+	// Zones maps time zone aliases to time zone names.
+	Zones map[string]string `json:"zones"`
+
+	// Any holds layouts to try, in order, before
+	// the built-in layouts of the any format.
+	Any []string `json:"any"`
+}
+
+// userConfig holds the configuration read by loadConfig.
+var userConfig config
+
+// userFormats maps the lower-case names of the formats
+// defined in the configuration file to their layouts.
+var userFormats = make(map[string]string)
+
+// configPath returns the path of the configuration file and
+// reports whether it was specified explicitly.
+func configPath() (string, bool, error) {
+	if path := os.Getenv("GODATE_CONFIG"); path != "" {
+		return path, true, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Join(dir, "godate", "config.json"), false, nil
+}
+
+// loadConfig reads the configuration file, if there is one,
+// into userConfig and userFormats.
+func loadConfig() error {
+	path, explicit, err := configPath()
+	if err != nil {
+		// No configuration directory, so no configuration.
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg config
+	if err := dec.Decode(&cfg); err != nil {
+		return fmt.Errorf("cannot parse %s: %v", path, err)
+	}
+	for name, layout := range cfg.Formats {
+		lname := strings.ToLower(name)
+		if _, ok := timeparse.Formats()[lname]; ok || lname == "json" {
+			return fmt.Errorf("%s: format %q has the same name as a built-in format", path, name)
+		}
+		if layout == "" {
+			return fmt.Errorf("%s: empty layout for format %q", path, name)
+		}
+		userFormats[lname] = layout
+	}
+	zones := make(map[string]string)
+	for alias, zone := range cfg.Zones {
+		zones[strings.ToLower(alias)] = zone
+	}
+	cfg.Zones = zones
+	userConfig = cfg
+	return nil
+}
+
+// applyConfig sets the -itz and -otz flags from the configuration
+// file if they have not been set and the -u flag was not given.
+// It should be called after the flags have been parsed.
+func applyConfig() {
+	if *utc {
+		return
+	}
+	if *tzIn == "" {
+		*tzIn = userConfig.ITZ
+	}
+	if *tzOut == "" {
+		*tzOut = userConfig.OTZ
+	}
+}
+
+// lookupUserFormat returns the layout for the named format
+// from the configuration file, or format itself if
+// there is no such format.
+func lookupUserFormat(format string) string {
+	if layout, ok := userFormats[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return format
+}
+
+// zoneAlias returns the time zone name for the given
+// alias from the configuration file, or name itself
+// if it isn't an alias.
+func zoneAlias(name string) string {
+	if zone, ok := userConfig.Zones[strings.ToLower(name)]; ok {
+		return zone
+	}
+	return name
+}
+
+// zoneAliases returns the configured time zone
+// aliases in alphabetical order.
+func zoneAliases() []string {
+	aliases := make([]string, 0, len(userConfig.Zones))
+	for alias := range userConfig.Zones {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
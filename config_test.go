@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testConfig = `{
+	"formats": {"AppLog": "2006-01-02 15:04:05.000"},
+	"itz": "UTC",
+	"otz": "Asia/Tokyo",
+	"zones": {"Office": "Europe/Dublin"},
+	"any": ["02/01/2006"]
+}`
+
+// patchConfig arranges for loadConfig to read a configuration
+// file with the given contents, and restores the configuration
+// when the test is done.
+func patchConfig(c *qt.C, contents string) string {
+	path := filepath.Join(c.Mkdir(), "config.json")
+	err := ioutil.WriteFile(path, []byte(contents), 0666)
+	c.Assert(err, qt.IsNil)
+	c.Setenv("GODATE_CONFIG", path)
+	c.Patch(&userConfig, config{})
+	c.Patch(&userFormats, make(map[string]string))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	c := qt.New(t)
+	patchConfig(c, testConfig)
+	err := loadConfig()
+	c.Assert(err, qt.IsNil)
+	c.Assert(userConfig, qt.DeepEquals, config{
+		Formats: map[string]string{"AppLog": "2006-01-02 15:04:05.000"},
+		ITZ:     "UTC",
+		OTZ:     "Asia/Tokyo",
+		Zones:   map[string]string{"office": "Europe/Dublin"},
+		Any:     []string{"02/01/2006"},
+	})
+	c.Assert(userFormats, qt.DeepEquals, map[string]string{
+		"applog": "2006-01-02 15:04:05.000",
+	})
+}
+
+func TestConfigFormats(t *testing.T) {
+	c := qt.New(t)
+	patchConfig(c, testConfig)
+	c.Assert(loadConfig(), qt.IsNil)
+	c.Assert(lookupUserFormat("APPLOG"), qt.Equals, "2006-01-02 15:04:05.000")
+	c.Assert(lookupUserFormat("rfc3339"), qt.Equals, "rfc3339")
+
+	parseTime, err := timeParser("applog", "UTC")
+	c.Assert(err, qt.IsNil)
+	t0, _, err := parseTime("2024-03-01 10:00:00.250")
+	c.Assert(err, qt.IsNil)
+	c.Assert(t0, qt.DeepEquals, time.Date(2024, 3, 1, 10, 0, 0, 250e6, time.UTC))
+
+	// The configured layouts are tried first by the any format.
+	parseTime, err = timeParser("any", "UTC")
+	c.Assert(err, qt.IsNil)
+	t0, format, err := parseTime("03/02/2024")
+	c.Assert(err, qt.IsNil)
+	c.Assert(t0, qt.DeepEquals, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC))
+	c.Assert(format, qt.Equals, "02/01/2006")
+}
+
+func TestConfigZones(t *testing.T) {
+	c := qt.New(t)
+	patchConfig(c, testConfig)
+	c.Assert(loadConfig(), qt.IsNil)
+	c.Assert(zoneAlias("OFFICE"), qt.Equals, "Europe/Dublin")
+	c.Assert(zoneAlias("tokyo"), qt.Equals, "tokyo")
+	c.Assert(zoneAliases(), qt.DeepEquals, []string{"office"})
+	loc, err := loadLocation("office")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loc.String(), qt.Equals, "Europe/Dublin")
+}
+
+func TestApplyConfig(t *testing.T) {
+	c := qt.New(t)
+	patchConfig(c, testConfig)
+	c.Assert(loadConfig(), qt.IsNil)
+
+	c.Patch(tzIn, "")
+	c.Patch(tzOut, "London")
+	c.Patch(utc, false)
+	applyConfig()
+	c.Assert(*tzIn, qt.Equals, "UTC")
+	c.Assert(*tzOut, qt.Equals, "London")
+
+	// The -u flag takes precedence over the configuration.
+	*tzIn, *tzOut, *utc = "", "", true
+	applyConfig()
+	c.Assert(*tzIn, qt.Equals, "")
+	c.Assert(*tzOut, qt.Equals, "")
+}
+
+var loadConfigErrorTests = []struct {
+	testName    string
+	contents    string
+	expectError string
+}{{
+	testName:    "bad-json",
+	contents:    `{"formats": `,
+	expectError: `cannot parse .*config.json: unexpected EOF`,
+}, {
+	testName:    "unknown-field",
+	contents:    `{"format": {}}`,
+	expectError: `cannot parse .*config.json: json: unknown field "format"`,
+}, {
+	testName:    "built-in-name",
+	contents:    `{"formats": {"RFC3339": "2006"}}`,
+	expectError: `.*config.json: format "RFC3339" has the same name as a built-in format`,
+}, {
+	testName:    "json-name",
+	contents:    `{"formats": {"json": "2006"}}`,
+	expectError: `.*config.json: format "json" has the same name as a built-in format`,
+}, {
+	testName:    "empty-layout",
+	contents:    `{"formats": {"mine": ""}}`,
+	expectError: `.*config.json: empty layout for format "mine"`,
+}}
+
+func TestLoadConfigError(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range loadConfigErrorTests {
+		c.Run(test.testName, func(c *qt.C) {
+			patchConfig(c, test.contents)
+			c.Assert(loadConfig(), qt.ErrorMatches, test.expectError)
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	c := qt.New(t)
+	path := patchConfig(c, "{}")
+	err := os.Remove(path)
+	c.Assert(err, qt.IsNil)
+
+	// A missing file named by $GODATE_CONFIG is an error.
+	c.Assert(loadConfig(), qt.ErrorMatches, `open .*config.json: no such file or directory`)
+
+	// A missing file in the default location is not.
+	c.Setenv("GODATE_CONFIG", "")
+	c.Setenv("XDG_CONFIG_HOME", c.Mkdir())
+	c.Setenv("HOME", c.Mkdir())
+	c.Assert(loadConfig(), qt.IsNil)
+	c.Assert(userFormats, qt.HasLen, 0)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rogpeppe/godate/timeformat"
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// timeDescription is printed by the json output format.
+type timeDescription struct {
+	RFC3339UTC string      `json:"rfc3339utc"`
+	RFC3339    string      `json:"rfc3339"`
+	Unix       json.Number `json:"unix"`
+	UnixMilli  json.Number `json:"unixmilli"`
+	UnixMicro  json.Number `json:"unixmicro"`
+	UnixNano   json.Number `json:"unixnano"`
+	Zone       string      `json:"zone"`
+	Abbrev     string      `json:"abbrev"`
+	Offset     int         `json:"offset"`
+	DST        bool        `json:"dst"`
+	Weekday    string      `json:"weekday"`
+	ISOYear    int         `json:"isoyear"`
+	ISOWeek    int         `json:"isoweek"`
+	YearDay    int         `json:"yearday"`
+	Format     string      `json:"format,omitempty"`
+}
+
+// formatJSON returns a JSON object describing t. The format
+// holds the format that t was parsed with, if known.
+func formatJSON(t time.Time, format string) (string, error) {
+	abbrev, offset := t.Zone()
+	isoYear, isoWeek := t.ISOWeek()
+	d := timeDescription{
+		RFC3339UTC: t.UTC().Format(time.RFC3339Nano),
+		RFC3339:    t.Format(time.RFC3339Nano),
+		Unix:       epochNumber(t, "unix"),
+		UnixMilli:  epochNumber(t, "unixmilli"),
+		UnixMicro:  epochNumber(t, "unixmicro"),
+		UnixNano:   epochNumber(t, "unixnano"),
+		Zone:       timeparse.ZoneName(t.Location()),
+		Abbrev:     abbrev,
+		Offset:     offset,
+		DST:        t.IsDST(),
+		Weekday:    t.Weekday().String(),
+		ISOYear:    isoYear,
+		ISOWeek:    isoWeek,
+		YearDay:    t.YearDay(),
+		Format:     formatName(format),
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// formatName returns the name of the known format with
+// the given layout, or the layout itself if there is none.
+// When several formats share the layout, as aliases do,
+// the first name in alphabetical order is used, preferring
+// the built-in formats to those in the configuration file.
+func formatName(layout string) string {
+	for _, formats := range []map[string]string{timeparse.Formats(), userFormats} {
+		if name := firstName(formats, layout); name != "" {
+			return name
+		}
+	}
+	return layout
+}
+
+// firstName returns the alphabetically first name in formats with
+// the given layout, or the empty string if there is none.
+func firstName(formats map[string]string, layout string) string {
+	first := ""
+	for name, f := range formats {
+		if f == layout && (first == "" || name < first) {
+			first = name
+		}
+	}
+	return first
+}
+
+// epochNumber returns t in the given epoch format as a JSON number.
+func epochNumber(t time.Time, format string) json.Number {
+	s, _ := timeparse.Format(t, format, timeformat.Style{})
+	return json.Number(s)
+}
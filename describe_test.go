@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFormatName(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(&userFormats, map[string]string{
+		"zz":      "2006-01-02",
+		"isodate": "2006-01-02",
+		"mine":    "Jan 2 15:04:05 2006",
+		"rfc":     "2006-01-02T15:04:05Z07:00",
+	})
+	// Run it a few times, as map iteration order varies.
+	for i := 0; i < 20; i++ {
+		c.Check(formatName("2006-01-02"), qt.Equals, "isodate")
+		c.Check(formatName("2006-01-02T15:04:05Z07:00"), qt.Equals, "rfc3339")
+		c.Check(formatName("Jan 2 15:04:05 2006"), qt.Equals, "mine")
+		c.Check(formatName("02/01/2006"), qt.Equals, "02/01/2006")
+	}
+}
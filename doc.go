@@ -3,17 +3,32 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
+
+	"github.com/rogpeppe/godate/timeformat"
+	"github.com/rogpeppe/godate/timeparse"
 )
 
 func usage() {
 	fmt.Fprintf(os.Stderr, `
 Usage:
-	godate [flags] [[time [+-]duration...]...]
+	godate [flags] [[time[@zone] [+-]duration... [@zone]]...]
 or:
 	godate tz [name...]
+or:
+	godate [flags] check [-strict] [-skew duration] -f file
+or:
+	godate [flags] merge [-prefix] file[=format][@zone]...
+or:
+	godate [flags] serve [-addr address]
+or:
+	godate completion bash|zsh|fish
+or:
+	godate [flags] -repl
 Flags:
 `[1:])
 	flag.PrintDefaults()
@@ -36,8 +51,9 @@ Using the -abs flag suppresses this behavior.
 
 The default input time format is the special format "any" which
 interprets the time according to the first format that parses OK from
-the following list or, if the time consists only of digits, the
-first of unixnano, unixmilli or unix that parses as a time outside
+the following list or, if the time is a UUID, ULID or ObjectId, the time embedded
+in it, or, if the time is a number, the
+first of unixnano, unixmicro, unixmilli or unix that parses as a time outside
 January 1970:
 
 	2006
@@ -46,16 +62,21 @@ January 1970:
 	2006-01-02 15:04:05Z
 	2006-01-02T15:04:05
 	2006-01-02 15:04:05
+	2006-01-02T15:04
+	2006-01-02 15:04
 	01-02 15:04
-	Jan 1
-	Jan 1 15:04
-	Jan 1 15:04:05
-	1 Jan
-	1 Jan 15:04
-	1 Jan 15:04:05
+	Jan 2
+	Jan 2 15:04
+	Jan 2 15:04:05
+	2 Jan
+	2 Jan 15:04
+	2 Jan 15:04:05
 	15:04
 	15:04:05
 
+It also recognizes quarters and fiscal years such as "2024Q3", "Q3 2024",
+"Q3", "FY2025", "FY25 Q1" and "FY25Q1", which specify the start of the period.
+
 As a special case, if the first argument is "tz", then godate prints all
 the available time zones (note: this uses an internal list and may not
 exactly match the system-provided time zones). If any arguments are
@@ -64,15 +85,24 @@ for timezone matching behavior) are printed.
 
 The format for a duration is either as accepted by Go's ParseDuration
 function (see https://golang.org/pkg/time/#Time.ParseDuration for details)
-or a similar format that specifies years (year, y), months (month, mo),
-weeks (week, w) or days (day, d). For example, this would print
+or a similar format that specifies years (year, y), quarters (quarter, q),
+months (month, mo), weeks (week, w) or days (day, d). For example, this would print
 the local time 1 month and 3 days hence and 20 minutes before the
 current time:
 
 	godate now +1month3days -20m
 
-Note that year, month, and week durations cannot be mixed with
-other duration kinds in the same argument.
+Units of both kinds can be mixed in the same argument, so +1d12h is a day
+and a half hence. A sign applies to the units that follow it up to the next
+sign, so +1d-1h is an hour short of a day hence.
+
+An argument of the form trunc:unit truncates the time to the start of
+the given unit, which is one of year (y), fiscalyear (fy), quarter (q),
+month (mo), week (w, starting on Monday) or day (d), or a duration
+such as 15m or h, counted from the start of the day. For example, this
+prints the start of the previous quarter:
+
+	godate now trunc:quarter -1q
 
 By default godate prints the current time in RFC3339 format in
 the local time zone. The -o flag can be used to change the format
@@ -81,6 +111,50 @@ for details). The reference date is:
 
 	Mon Jan 2 15:04:05 -0700 MST 2006
 
+As well as the reference date elements, layouts may contain these elements,
+which can be used for both printing and parsing:
+
+	%%Q  the quarter, from 1 to 4
+	%%F  the fiscal year
+	%%f  the last two digits of the fiscal year
+	%%W  the week of the fiscal year, as two digits
+	%%V  the ISO 8601 week number, as two digits
+	%%G  the ISO 8601 week-numbering year
+	%%u  the day of the week, from 1 (Monday) to 7 (Sunday)
+	%%w  the day of the week, from 0 (Sunday) to 6 (Saturday)
+	%%j  the day of the year, as three digits
+	%%i  the hour on a 12-hour clock followed by the minutes unless they're zero (3 or 3:30)
+	%%o  the ordinal suffix of the day of the month (st, nd, rd or th)
+	%%%%  a literal percent sign
+
+For example:
+
+	godate -o 'report-%%G-W%%V.csv'
+	godate -o 'Monday, January 2%%o at %%ipm' 2024-03-03T15:00:00Z
+
+When parsing, the ISO week (with the ISO year, or else the year) or the day
+of the year specifies the date if the layout has no month or day.
+Otherwise, the day of the week, ordinal suffix and other elements
+must agree with the date.
+
+A backslash in a layout makes the character after it literal, so digits and
+words that would otherwise be taken as part of the reference date can be
+used as text. It is usually enough to escape the first character of a word:
+
+	godate -o 'Report \1 of 2006, \Monday edition'
+
+The fiscal year starts in the month given by the -fiscal flag (January by
+default) and is named after the calendar year in which it ends, so with
+"-fiscal oct", FY2025 starts on the 1st of October 2024. Quarters are
+counted from the start of the fiscal year, as are weeks, so week 1 starts on
+its first day. Fiscal periods are always in the Gregorian calendar.
+When parsing, a fiscal year, quarter or week specifies the start
+of that period; with a quarter or week but no fiscal year, the year (2006)
+is taken as the fiscal year. For example:
+
+	godate -fiscal oct -o 'FY%%f Q%%Q' 2024-11-05
+	godate -fiscal oct -o 2006-01-02 'FY25 Q2'
+
 The format may also be the name of one of the predefined format
 constants in the time package (case-insensitive), in which case that format will be used.
 The supported predefined names are:
@@ -91,7 +165,11 @@ The supported predefined names are:
 		format string
 	}
 	var formats []format
-	for name, f := range knownFormats {
+	for name, f := range timeparse.Formats() {
+		formats = append(formats, format{name, f})
+	}
+	formats = append(formats, format{"json", "custom"})
+	for name, f := range userFormats {
 		formats = append(formats, format{name, f})
 	}
 	sort.Slice(formats, func(i, j int) bool {
@@ -99,20 +177,279 @@ The supported predefined names are:
 	})
 	w := tabwriter.NewWriter(os.Stderr, 4, 4, 1, ' ', 0)
 	for _, f := range formats {
-		fmt.Fprintf(w, "\t%s\t%s\n", f.name, f.format)
+		if _, ok := userFormats[f.name]; ok {
+			fmt.Fprintf(w, "\t%s\t%s\t(config)\n", f.name, f.format)
+		} else {
+			fmt.Fprintf(w, "\t%s\t%s\n", f.name, f.format)
+		}
 	}
 	w.Flush()
 
 	fmt.Fprintf(os.Stderr, `
 
+Month and weekday names and the AM/PM markers are in English unless the -locale
+flag is given, in which case they are printed in the given language, and parsed
+in either that language or English (case-insensitively, and with or without the
+trailing period of abbreviations such as "févr."). This applies to all layouts,
+including those used by the any format. Names such as "de_DE.UTF-8" are
+treated as the plain language. The names come from the Unicode CLDR; the
+supported languages are: %s.
+
+	godate -locale fr -i '2 Jan 2006' -o 'Monday 2 January 2006' '5 févr. 2024'
+`, strings.Join(timeformat.LocaleNames(), ", "))
+
+	fmt.Fprintf(os.Stderr, `
+
+By default dates are in the Gregorian calendar. The -ocal flag prints dates in
+another calendar and the -ical flag reads them in another calendar (including
+the layouts used by the any format). The year, month and day in the layout are
+taken to be in the calendar, so, for example, "-ocal hebrew -o '2 January 2006'"
+prints "1 Adar II 5784" for 2024-03-11. When reading dates in a calendar, the layout
+must include the year and either the month and day or the day of the year.
+The supported calendars are:
+
+	buddhist   Thai solar calendar: the Gregorian calendar with years counted from 543 BCE
+	gregorian  the Gregorian calendar
+	hebrew     Hebrew calendar; months are numbered from Tishrei (in leap years, 6 is Adar I and 7 Adar II)
+	islamic    tabular Islamic (civil Hijri) calendar
+	isoweek    ISO 8601 week date: the month is the week number and the day is the day of the week (Monday is 1)
+	japanese   Japanese imperial calendar; years are printed after the era name ("Reiwa 6", or "R06" for 06)
+	persian    Persian (Solar Hijri) calendar
+	roc        Republic of China (Minguo) calendar
+	umalqura   Umm al-Qura Islamic calendar used in Saudi Arabia (1356 to 1500 AH only)
+
+For example:
+
+	godate -ocal isoweek -o 2006-W01-2 now
+	godate -ical persian -i 2006/01/02 -o 2006-01-02 1403/07/12
+
+The -locale flag applies to weekday names and to the months of calendars that use
+the Gregorian months; other calendars have their own month names, which are printed
+in full for both January and Jan.
+
 The unix, unixmilli, unixmicro and unixnano formats are special cases that print the number of seconds,
 milliseconds, microseconds or nanoseconds since the Unix epoch (Jan 1st 1970). The "go" format is the
 format used by the time package to print times by default.
 
+When parsing, these formats accept numbers with a fractional part and an exponent (for example,
+1612345678.123456 or 1.6e9), which are converted exactly to the nearest nanosecond below.
+The unixfloat format prints the number of seconds since the Unix epoch with a fractional part
+of up to nine digits; "unixfloat:N" prints exactly N decimal places.
+
+The json output format prints a JSON object on a single line for each time,
+describing it in several ways:
+
+	rfc3339utc  the time in RFC3339 format in UTC
+	rfc3339     the time in RFC3339 format in the output time zone
+	unix        seconds since the Unix epoch (also unixmilli, unixmicro and unixnano)
+	zone        the name of the output time zone
+	abbrev      the time zone abbreviation, such as CET
+	offset      the time zone offset in seconds east of UTC
+	dst         whether daylight saving time is in effect
+	weekday     the day of the week, such as Monday
+	isoyear     the ISO 8601 year (see isoweek)
+	isoweek     the ISO 8601 week number
+	yearday     the day of the year, starting at 1
+	format      the input format that was used to parse the time
+
+This is useful for extracting fields with tools such as jq.
+
+Other formats count units since other epochs:
+
+	ntp        seconds since 1900-01-01 (NTP)
+	ntp64      64-bit NTP fixed point timestamp since 1900-01-01, as hex seconds.fraction
+	filetime   100ns intervals since 1601-01-01 (Windows FILETIME)
+	dotnet     100ns ticks since 0001-01-01 (.NET DateTime.Ticks)
+	cocoa      seconds since 2001-01-01 (Apple Cocoa and Core Data)
+	webkit     microseconds since 1601-01-01 (Chrome and WebKit)
+	gps        seconds since 1980-01-06, including leap seconds (GPS time)
+
+A custom epoch can be specified as "epoch:date/unit", where the date is in the form 2006-01-02,
+2006-01-02T15:04:05 or RFC3339 (UTC is assumed if no time zone is given) and the unit is a
+duration as accepted by Go's ParseDuration, with a leading 1 implied (for example, s, ms, us, ns
+or 100ns). The unit defaults to seconds. For example, "epoch:2001-01-01/ms" counts milliseconds
+since the start of 2001.
+
+Some formats extract the creation time embedded in an identifier:
+
+	uuid       version 1, 6 or 7 UUID
+	ulid       ULID
+	objectid   MongoDB ObjectId
+	snowflake  Twitter snowflake ID
+	discord    Discord snowflake ID
+
+A snowflake with a different epoch can be specified as "snowflake:epoch", where the
+epoch is in milliseconds since the Unix epoch or is a date as for "epoch:" above.
+When used as output formats, these print the smallest identifier that could have been
+created at the time, which is useful for range queries on tables keyed by identifier.
+The uuidv7max and ulidmax formats print the largest identifier instead.
+The "any" format recognizes UUIDs, ULIDs and ObjectIds.
+
+The excel, excel1904, lotus and libreoffice formats are spreadsheet serial dates: the number of days
+since an epoch, with the time of day held as a fraction of a day (for example 45292.5 is noon
+on the 1st of January 2024 in the excel system). Serial dates hold wall clock times, so they are
+interpreted in the -itz time zone and printed in the -otz time zone. The excel and lotus formats
+use the 1900 date system of Excel and Lotus 1-2-3, which counts the non-existent date 29th February
+1900 as day 60; godate reads day 60 as the 1st of March 1900, the same as day 61, and never prints it.
+Excel1904 is the 1904 date system used by older Mac versions of Excel; libreoffice counts from
+30th December 1899 without the leap year bug, as do LibreOffice, Google Sheets and OLE automation
+dates. Only libreoffice allows negative serial dates: the integer part counts whole days back
+from the epoch and the fraction still adds the time of day, so -1.5 is day -2 plus half a day,
+noon on the 28th of December 1899, rather than midnight on the 29th. If the -serial flag names
+one of these formats, the "any" input format will interpret numbers with no more than five
+integer digits as serial dates in that system.
+
 When one or more arguments are provided, they will be used as the time
 to print instead of the current time. The -in flag can be used to specify
 what format to interpret these arguments in. Again, unix, unixmilli, unixmicro and unixnano
-can be used to specify input in seconds or nanoseconds since the Unix epoch.
+and the other epoch formats can be used to specify input as a count since an epoch.
+
+The -f flag reads times from a file, one per line, instead of from the arguments.
+With the -replace flag, the lines need not hold only a time: each line is
+searched for text that parses according to the -i format, each
+such time is replaced with the time printed according to the -o flag,
+and all other text is left untouched. For example, this prints
+a log file with its millisecond timestamps shown as local times:
+
+	godate -f app.log -replace -i unixmilli -o stampmilli
+
+When searching with the "any" format, bare years and numbers with fewer
+than ten digits are not treated as times.
+When searching with an epoch or spreadsheet format, only numbers with
+as many digits as the counts of times between 2001 and 2286 are treated as
+times, and numbers next to a "-", ":" or "." (other than a full stop
+ending a sentence) are not, so the digits within dates, times of day and
+dotted numbers are left alone.
+
+With the -col flag, the file is read as delimited records (comma-separated
+by default; see the -delim flag) and only the times in the selected columns are
+converted; the records are written back out with the same delimiter,
+quoted as necessary. The -col flag can be repeated to select
+several columns, each of the form:
+
+	column[=format][@zone]
+
+where column is a column number starting at 1 or, when the -header flag
+is given, the name of a column in the first record. The format and zone,
+if present, override the -i and -itz flags for that column. A backslash
+makes the character after it part of the column name, so the name "a=b"
+can be selected with "a\=b=unix". For example:
+
+	godate -f export.csv -header -col created=unixmilli -col 3=rfc1123@Europe/Paris -o 2006-01-02
+
+With the -json flag, the file is read as a sequence of JSON values (for example
+a single JSON document or newline-delimited JSON) and the times found at the
+given path are converted. Each value is written out as compact JSON on its own line.
+The -json flag can be repeated; like -col, each path may be followed by =format
+and @zone. A path is a sequence of .key to select an object member, [] to select all
+elements of an array, or [n] to select element n; the path "." selects the top level value.
+Values that don't parse as times are left alone. Times are written as JSON strings
+unless the -jsonnum flag is given and the output format is an epoch or spreadsheet
+format other than ntp64 (for example -o unixmilli), in which case they are written
+as JSON numbers. Layouts that print only digits, such as 0102, still give strings.
+
+	godate -f events.ndjson -json .ts=unixmilli -json '.spans[].start' -otz UTC
+
+The -after, -before and -between flags restrict the -f output to times inside
+a window: -after includes times at or after the given time, -before includes
+times strictly before it, and -between start..end is equivalent to both.
+The window limits are read in the any format, interpreted in the -itz time zone,
+and may be followed by deltas, so "now -2h" means two hours ago; a limit
+consisting only of deltas is relative to the current time.
+In -replace mode, the first time found on each line decides whether the line
+is included; with -col, the first selected column decides and with -json, the
+first time found at the given paths. Lines and records without a time go with
+the most recent one that had a time, so continuation lines such as stack traces
+stay with their log entry. The -orig flag prints the original input rather than
+the converted times, which makes godate usable as a filter for log slices:
+
+	godate -f app.log -replace -between '2024-03-01 10:00:00..2024-03-01 10:15:00' -orig
+	godate -f app.log -replace -after 'now -2h' -orig
+
+The -stats flag prints a summary of the times read with -f instead of
+the times themselves: the count, the earliest and latest times, the span between them,
+the median interval between successive times and the largest gaps
+(the -gaps flag sets how many). With -replace, -col and -json, the time
+of each record is its first time, as for -after and -before, which also
+apply. The -hist flag adds a histogram of the times with buckets of the given
+size, which is either a delta such as 1m, 1h or 1d or "weekday" to
+count the times on each day of the week. Buckets are calculated in the -otz time zone.
+The output is tab-separated, with times printed in the -o format:
+
+	godate -f jobs.log -replace -stats -gaps 10 -hist 1h
+
+If the first argument is "check", godate checks that the times read from
+the -f file, one per line, never go backwards, printing each line with a time
+earlier than the time on the most recent line before it, along with the size of the
+regression. Flags may also follow the "check" argument. The -strict flag also
+reports a time that is equal to the previous time and the -skew flag allows times to go
+backwards by up to the given duration. With -replace, the first time on each line is
+checked and lines without a time are ignored; otherwise lines that cannot be parsed are
+reported too. Godate exits with status 1 if any line was reported,
+which makes it suitable for use in scripts and CI:
+
+	godate -i unixmilli check -f fixtures/events.txt -strict
+	godate check -f app.log -replace -skew 2s
+
+If the first argument is "merge", godate merges the named files into a single
+stream in time order, like "sort -m" but comparing times. The time of each line
+is the first time found in it, as for -replace, and lines without a time stay
+attached to the line before them, so multi-line entries are kept together.
+Entries with equal times are written in the order of the files on the command line.
+Like -col, each file name may be followed by =format and @zone to override the -i and -itz
+flags for that file ("-" means standard input). With -prefix, each entry is prefixed
+with its time printed in the -o format and -otz time zone, which makes entries
+from files with different formats easier to compare:
+
+	godate -otz UTC merge -prefix api.log@America/New_York worker.log=unixmilli
+
+With the -repl flag, godate starts an interactive session. Each line
+holds times followed by deltas and truncations, as on the command line
+(use quotes for times containing spaces), or "tz" followed by time
+zone names. The previous result is available as "_", and a line such as
+":otz tokyo" changes a flag for the rest of the session (":otz" alone
+prints its value; ":help" lists the commands). When standard input is
+a terminal, tab completes time zone, format, calendar and locale names
+and delta and truncation units, and the arrow keys recall previous lines.
+This uses the stty command; without it, lines are read as the terminal
+delivers them, with no completion or history. For example:
+
+	godate -repl
+	godate> '2024-03-01 10:00' +90m
+	godate> :otz tokyo
+	godate> _ trunc:d
+
+The serve subcommand serves an HTTP API on the -addr address, parsing
+and printing times exactly as the command does. Each endpoint takes its
+arguments as query parameters and replies with a JSON object, or with
+an object holding an error field and status 400 on failure. The i, itz, o
+and otz parameters override the flags of the same names for a request.
+Only GET and HEAD requests are accepted, request bodies of more than 64KB
+are refused, and slow clients are disconnected after a timeout.
+
+	/health                     {"status": "ok"}
+	/parse?t=time               the time described as by -o json
+	/format?t=time              the time printed in the o format
+	/delta?t=time&d=delta...    the time adjusted by deltas and truncations
+	/diff?from=time&to=time     the difference between the times
+	/zones?name=name            the zones matching name, as by "godate tz"
+
+For example:
+
+	godate -otz UTC serve -addr :8080
+	curl 'localhost:8080/delta?t=now&d=%%2B1mo&d=trunc:d&o=unix'
+
+The completion subcommand prints a script that makes the bash, zsh or
+fish shell complete godate's flags, subcommands, format names, time zone
+names (including configured aliases, and from the last part of the name,
+so "tok" completes to "Asia/Tokyo"), calendars, locales and delta and
+truncation units. The completions are produced by godate itself, so
+they're always in step with it. For example, add one of these
+to the shell's startup file:
+
+	source <(godate completion bash)
+	source <(godate completion zsh)
+	godate completion fish | source
 
 Time zones can be specified with the -itz and -otz flags. As a convenience,
 if the specified zone does not exactly match one of the known zones,
@@ -120,37 +457,114 @@ a case-insensitive match is tried, and then a substring match.
 If the result is unambiguous, the matching time zone is used
 (for example "-otz london" can be used to select the "Europe/London"
 time zone).
+
+A time zone can also be a fixed offset from UTC, optionally preceded by
+UTC or GMT, such as "+05:30", "UTC-3" or "GMT+8". Positive offsets are
+east of UTC, as in RFC3339 times. Beware that this is the opposite of the
+Etc/GMT zones in the time zone database, which follow the POSIX convention,
+so "GMT+8" is 8 hours ahead of UTC but "Etc/GMT+8" is 8 hours behind.
+A time zone can also be a POSIX TZ string, as used by the TZ environment
+variable, such as "EST5EDT,M3.2.0,M11.1.0" or "CET-1CEST,M3.5.0,M10.5.0/3",
+whose offsets are west of UTC. When a TZ string names a daylight saving time
+zone without giving its rules, the US rules are used. For example:
+
+	godate -otz 'CET-1CEST,M3.5.0,M10.5.0/3' 2024-07-01T12:00:00Z
+
+A time argument can also carry its own time zone, overriding -itz, either
+after an @ sign or, for a time that contains spaces, after a final space,
+where zone names are matched in the same way. An argument of the form
+@zone following a time (and any deltas) converts the time to that zone
+and prints it there rather than in the -otz zone. Otherwise, times
+are printed in the zone they were read in unless -otz is given. For
+example, this prints 9am in Tokyo and 5pm in London, both in local time,
+and then 10am in Paris in Tokyo time:
+
+	godate -otz local '09:00@tokyo' '17:00@london'
+	godate '2024-03-01 10:00 Europe/Paris' @tokyo
+
+The ixdtf format reads and prints times in the Internet Extended Date/Time
+Format of RFC 9557, in which an RFC3339 time is followed by its time zone
+name in brackets, as in "2024-03-10T03:30:00-04:00[America/New_York]",
+and possibly by other annotations such as [u-ca=hebrew], which are ignored.
+The any format also recognizes these times. Unlike elsewhere, the zone name
+must match exactly. When the offset doesn't match the time zone, the time is
+rejected unless the -ixdtf flag says otherwise: "use" uses the offset to find
+the time and shows it in the zone, "ignore" ignores the offset and reads the
+time as a wall clock time in the zone, and "prefer" uses the offset only when
+it's valid for the zone. A mismatch is always an error when the zone is marked
+critical with an exclamation mark, as in [!America/New_York], and so is any
+other critical annotation that godate doesn't understand. When printing, the
+offset is used in place of the zone name for times without one.
+
+	godate -o ixdtf -otz America/New_York 2024-03-10T12:00:00Z
+	godate -ixdtf use '2024-03-10T02:30:00-05:00[America/New_York]'
+
+Godate reads a configuration file in JSON format from $GODATE_CONFIG or,
+if that's not set, from godate/config.json in the user's configuration
+directory (for example $XDG_CONFIG_HOME or ~/.config on Linux). It may hold
+named formats, which can be used with the -i and -o flags and are shown
+above marked "(config)", default time zones for the -itz and -otz flags,
+which apply unless the flags or -u are given, time zone aliases, which
+can be used wherever a time zone can, and layouts for the any format to
+try, in order, before the built-in layouts. For example:
+
+	{
+		"formats": {"applog": "2006-01-02 15:04:05.000"},
+		"itz": "UTC",
+		"otz": "Local",
+		"zones": {"office": "Europe/Dublin"},
+		"any": ["02/01/2006", "02/01/2006 15:04"]
+	}
 `[1:])
+	if path, _, err := configPath(); err == nil {
+		fmt.Fprintf(os.Stderr, "\nThe configuration file is %s.\n", path)
+	}
+	if aliases := zoneAliases(); len(aliases) > 0 {
+		fmt.Fprintf(os.Stderr, "\nThe configured time zone aliases are:\n\n")
+		w := tabwriter.NewWriter(os.Stderr, 4, 4, 1, ' ', 0)
+		for _, alias := range aliases {
+			fmt.Fprintf(w, "\t%s\t%s\n", alias, userConfig.Zones[alias])
+		}
+		w.Flush()
+	}
+	if len(userConfig.Any) > 0 {
+		fmt.Fprintf(os.Stderr, "\nThe configured layouts for the any format are:\n\n")
+		for _, layout := range userConfig.Any {
+			fmt.Fprintf(os.Stderr, "\t%s\n", layout)
+		}
+	}
 	os.Exit(2)
 }
 
-func printZones(args []string) {
+// printZones prints the time zones matching any of the
+// given arguments to w, or all time zones if there are none.
+func printZones(w io.Writer, args []string) error {
 	if len(args) == 0 {
 		args = []string{""}
 	}
 	var tzs []string
 	zones := make(map[string]bool)
 	for _, arg := range args {
-		for _, tz := range zoneMatch(arg) {
+		for _, tz := range timeparse.MatchZones(arg) {
 			zones[tz] = true
 		}
 	}
 	if len(zones) == 0 {
-		fatalf("no matching time zones found")
+		return fmt.Errorf("no matching time zones found")
 	}
 	tzs = make([]string, 0, len(zones))
 	for zone := range zones {
 		tzs = append(tzs, zone)
 	}
 	sort.Strings(tzs)
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 1, ' ', 0)
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
 	for _, tz := range tzs {
-		linked := zoneNames[tz]
+		linked := timeparse.ZoneLink(tz)
 		if !*alias || linked == "" {
-			fmt.Fprintf(w, "%s\n", tz)
+			fmt.Fprintf(tw, "%s\n", tz)
 		} else {
-			fmt.Fprintf(w, "%s\t%s\n", tz, linked)
+			fmt.Fprintf(tw, "%s\t%s\n", tz, linked)
 		}
 	}
-	w.Flush()
+	return tw.Flush()
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// replaceTimes returns line with all the text matching pat that
+// parses as a time replaced by the formatted time.
+// Matches that are part of a larger word or number are ignored.
+// It also returns the first time found and reports whether
+// there was one.
+func replaceTimes(
+	line string,
+	pat *regexp.Regexp,
+	parseTime parseFunc,
+	formatTime formatFunc,
+) (string, time.Time, bool) {
+	var buf strings.Builder
+	var first time.Time
+	found := false
+	last := 0
+	for _, m := range pat.FindAllStringIndex(line, -1) {
+		if m[0] == m[1] || !isBoundary(line, m[0], m[1]) {
+			continue
+		}
+		t, tf, err := parseTime(line[m[0]:m[1]])
+		if err != nil {
+			continue
+		}
+		if !found {
+			first, found = t, true
+		}
+		s, err := formatTime(t, tf)
+		if err != nil {
+			continue
+		}
+		buf.WriteString(line[last:m[0]])
+		buf.WriteString(s)
+		last = m[1]
+	}
+	if last == 0 {
+		return line, first, found
+	}
+	buf.WriteString(line[last:])
+	return buf.String(), first, found
+}
+
+// findTime returns the first time in line that matches pat
+// and reports whether there was one.
+func findTime(line string, pat *regexp.Regexp, parseTime parseFunc) (time.Time, bool) {
+	for _, m := range pat.FindAllStringIndex(line, -1) {
+		if m[0] == m[1] || !isBoundary(line, m[0], m[1]) {
+			continue
+		}
+		if t, _, err := parseTime(line[m[0]:m[1]]); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isBoundary reports whether line[start:end] is
+// not immediately preceded or followed by
+// a letter or digit. When the text is a plain number,
+// it must not be next to a '-', ':' or '.' either,
+// so that the digits within dates, times of day and
+// dotted numbers aren't taken to be times;
+// a full stop that ends a sentence may follow it.
+func isBoundary(line string, start, end int) bool {
+	var before, after byte
+	if start > 0 {
+		before = line[start-1]
+	}
+	if end < len(line) {
+		after = line[end]
+	}
+	if isAlnum(before) || isAlnum(after) {
+		return false
+	}
+	if !isNumber(line[start:end]) {
+		return true
+	}
+	switch {
+	case before == '-' || before == ':' || before == '.':
+		return false
+	case after == '-' || after == ':':
+		return false
+	case after == '.':
+		return end+1 == len(line) || !isDigit(line[end+1])
+	}
+	return true
+}
+
+// isNumber reports whether s holds only digits,
+// signs and decimal points.
+func isNumber(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isDigit(c) && c != '-' && c != '+' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var replaceTimesTests = []struct {
+	testName string
+	format   string
+	line     string
+	expect   string
+}{{
+	// The example from the documentation.
+	testName: "unixmilli",
+	format:   "unixmilli",
+	line:     "GET /status 200 at 2024-01-02T03:04:05Z took 12ms 1700000000000",
+	expect:   "GET /status 200 at 2024-01-02T03:04:05Z took 12ms 2023-11-14T22:13:20Z",
+}, {
+	testName: "unix-sentence",
+	format:   "unix",
+	line:     "started at 1700000000.",
+	expect:   "started at 2023-11-14T22:13:20Z.",
+}, {
+	testName: "unix-within-dotted-number",
+	format:   "unix",
+	line:     "version 1700000000.1.2 and 10.1700000000",
+	expect:   "version 1700000000.1.2 and 10.1700000000",
+}, {
+	testName: "unix-within-time",
+	format:   "unix",
+	line:     "2024-01-02T1700000000:1700000000-1700000000",
+	expect:   "2024-01-02T1700000000:1700000000-1700000000",
+}, {
+	testName: "unix-fraction",
+	format:   "unix",
+	line:     "[1700000000.5] x",
+	expect:   "[2023-11-14T22:13:20.5Z] x",
+}, {
+	testName: "excel",
+	format:   "excel",
+	line:     "row 12: 45292.5",
+	expect:   "row 12: 2024-01-01T12:00:00Z",
+}, {
+	testName: "any",
+	format:   "any",
+	line:     "from 2024-01-02T03:04:05Z to 1700000000",
+	expect:   "from 2024-01-02T03:04:05Z to 2023-11-14T22:13:20Z",
+}}
+
+func TestReplaceTimes(t *testing.T) {
+	c := qt.New(t)
+	formatTime := func(t time.Time, format string) (string, error) {
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}
+	for _, test := range replaceTimesTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p, err := newParser(test.format)
+			c.Assert(err, qt.IsNil)
+			p.Location = time.UTC
+			pat, err := p.Pattern()
+			c.Assert(err, qt.IsNil)
+			got, _, _ := replaceTimes(test.line, pat, p.Parse, formatTime)
+			c.Assert(got, qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestFindTime(t *testing.T) {
+	c := qt.New(t)
+	p, err := newParser("unixmilli")
+	c.Assert(err, qt.IsNil)
+	pat, err := p.Pattern()
+	c.Assert(err, qt.IsNil)
+	_, ok := findTime("status 200 at 2024-01-02T03:04:05Z", pat, p.Parse)
+	c.Assert(ok, qt.IsFalse)
+	got, ok := findTime("status 200 at 1700000000000", pat, p.Parse)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(got.Equal(time.Unix(1700000000, 0)), qt.IsTrue)
+}
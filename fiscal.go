@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/godate/timeformat"
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// fiscalStart returns the month named by the -fiscal flag.
+func fiscalStart() (time.Month, error) {
+	s := *fiscalFlag
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 || n > 12 {
+			return 0, fmt.Errorf("fiscal year start month %d out of range", n)
+		}
+		return time.Month(n), nil
+	}
+	for i, name := range timeformat.English.Months {
+		if strings.EqualFold(s, name) || strings.EqualFold(s, timeformat.English.ShortMonths[i]) {
+			return time.Month(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fiscal year start month %q", s)
+}
+
+// isTruncation reports whether the argument s
+// truncates the preceding time.
+func isTruncation(s string) bool {
+	return strings.HasPrefix(s, "trunc:")
+}
+
+// truncate truncates t according to the argument s, of the form trunc:unit,
+// where unit is as accepted by timeparse.Truncate.
+func truncate(t time.Time, s string) (time.Time, error) {
+	start, err := fiscalStart()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timeparse.Truncate(t, strings.TrimPrefix(s, "trunc:"), start)
+}
@@ -1,366 +1,335 @@
 package main
 
 import (
-	"bufio"
-	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/rogpeppe/godate/timeformat"
+	"github.com/rogpeppe/godate/timeparse"
 )
 
-//go:generate bash getzones.bash
-
 // Possible TODOs:
-// - support for rounding and truncation (how would that work syntactically?).
+// - support for rounding (like trunc:unit).
 //
-// 	godate now +5m round:1h  trunc:1day
+// 	godate now +5m round:1h
 //
 // 	rounding for date durations might be hard.
 
 var (
-	outFormat = flag.String("o", "rfc3339nano", "use Go-style time format string (or name)")
-	inFormat  = flag.String("i", "any", "interpret argument times as this Go-style format (or name)")
-	file      = flag.String("f", "", "read times from named file, one per line; - means stdin")
-	tzIn      = flag.String("itz", "", "interpret argument times in this time zone location (default local)")
-	tzOut     = flag.String("otz", "", "print times in this time zone location (default local)")
-	alias     = flag.Bool("alias", false, "when printing time zone matches, also print time zone aliases")
-	utc       = flag.Bool("u", false, "default to UTC time zone rather than local")
-	abs       = flag.Bool("abs", false, "suppress filling incomplete info from current time")
+	outFormat   = flag.String("o", "rfc3339nano", "use Go-style time format string (or name)")
+	inFormat    = flag.String("i", "any", "interpret argument times as this Go-style format (or name)")
+	file        = flag.String("f", "", "read times from named file, one per line; - means stdin")
+	tzIn        = flag.String("itz", "", "interpret argument times in this time zone location (default local)")
+	tzOut       = flag.String("otz", "", "print times in this time zone location (default local)")
+	alias       = flag.Bool("alias", false, "when printing time zone matches, also print time zone aliases")
+	utc         = flag.Bool("u", false, "default to UTC time zone rather than local")
+	abs         = flag.Bool("abs", false, "suppress filling incomplete info from current time")
+	replace     = flag.Bool("replace", false, "with -f, replace times found anywhere within each line, leaving other text untouched")
+	delim       = flag.String("delim", ",", "with -col, the field delimiter (\"tab\" means a tab character)")
+	header      = flag.Bool("header", false, "with -col, treat the first record as a header")
+	jsonNum     = flag.Bool("jsonnum", false, "with -json, write numeric times as JSON numbers rather than strings")
+	afterFlag   = flag.String("after", "", "with -f, only include times at or after this time (a time followed by optional deltas)")
+	beforeFlag  = flag.String("before", "", "with -f, only include times before this time (a time followed by optional deltas)")
+	betweenFlag = flag.String("between", "", "with -f, only include times in the range `start..end`")
+	orig        = flag.Bool("orig", false, "with -f, print the original input rather than reformatted times")
+	showStats   = flag.Bool("stats", false, "with -f, print statistics about the times instead of the times themselves")
+	gaps        = flag.Int("gaps", 5, "with -stats, the number of largest gaps between times to print")
+	hist        = flag.String("hist", "", "with -stats, print a histogram with buckets of this `size` (a delta such as 1h, or weekday)")
+	strict      = flag.Bool("strict", false, "with check, require times to be strictly increasing")
+	skew        = flag.Duration("skew", 0, "with check, allow times to go backwards by up to this duration")
+	prefix      = flag.Bool("prefix", false, "with merge, prefix each entry with its time printed in the -o format")
+	calIn       = flag.String("ical", "", "interpret dates in argument times in this calendar (default gregorian)")
+	calOut      = flag.String("ocal", "", "print dates in this calendar (default gregorian)")
+	fiscalFlag  = flag.String("fiscal", "jan", "the `month` in which the fiscal year starts, by name or number")
+	localeName  = flag.String("locale", "en", "use month and weekday names in this language when parsing and printing times")
+	addr        = flag.String("addr", "localhost:8080", "with serve, listen on this `address`")
+	replMode    = flag.Bool("repl", false, "start an interactive session, reading times and commands from standard input")
+	anySerial   = flag.String("serial", "", "in the any format, interpret short numbers as dates in this spreadsheet date system")
+	ixdtfPolicy = flag.String("ixdtf", "reject", "how to interpret ixdtf times whose offset doesn't match their time zone: reject, use, ignore or prefer")
 )
 
-var knownFormats = map[string]string{
-	"ansic":       time.ANSIC,
-	"git":         "Mon Jan _2 15:04:05 2006 -0700",
-	"unixdate":    time.UnixDate,
-	"rubydate":    time.RubyDate,
-	"rfc822":      time.RFC822,
-	"rfc822z":     time.RFC822Z,
-	"rfc850":      time.RFC850,
-	"rfc1123":     time.RFC1123,
-	"rfc1123z":    time.RFC1123Z,
-	"rfc3339":     time.RFC3339,
-	"rfc3339nano": time.RFC3339Nano,
-	"kitchen":     time.Kitchen,
-	"stamp":       time.Stamp,
-	"stampmilli":  time.StampMilli,
-	"stampmicro":  time.StampMicro,
-	"stampnano":   time.StampNano,
-	"go":          "2006-01-02 15:04:05.999999999 -0700 MST",
-	"unix":        "custom",
-	"unixmilli":   "custom",
-	"unixmicro":   "custom",
-	"unixnano":    "custom",
-	"any":         "custom",
+var (
+	columns   stringsFlag
+	jsonPaths stringsFlag
+)
+
+func init() {
+	flag.Var(&columns, "col", "with -f, convert times in this `column` of delimited input; can be repeated")
+	flag.Var(&jsonPaths, "json", "with -f, convert times at this `path` in JSON input; can be repeated")
+}
+
+// stringsFlag implements flag.Value by accumulating
+// all the values it's set to.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
 }
 
+// parseFunc parses a time. As well as the time, it returns the format
+// that the time was parsed with, which is useful to know when
+// the time was parsed with the any format.
+type parseFunc func(s string) (t time.Time, format string, err error)
+
+// formatFunc formats a time. The format argument holds the
+// format that the time was parsed with, if known.
+type formatFunc func(t time.Time, format string) (string, error)
+
 func main() {
 	flag.Usage = usage
+	// Report a bad configuration file only after parsing
+	// the flags, so that -h works regardless.
+	configErr := loadConfig()
 	flag.Parse()
+	if configErr != nil {
+		fatalf("cannot load configuration: %v", configErr)
+	}
+	switch flag.Arg(0) {
+	case "check":
+		runCheck(flag.Args()[1:])
+		return
+	case "merge":
+		runMerge(flag.Args()[1:])
+		return
+	case "serve":
+		runServe(flag.Args()[1:])
+		return
+	case "completion":
+		runCompletion(flag.Args()[1:])
+		return
+	case "__complete":
+		runComplete(flag.Args()[1:])
+		return
+	}
+	applyConfig()
 	formatTime, err := formatter()
 	if err != nil {
 		fatalf("%v", err)
 	}
-	parseTime, err := timeParser()
+	parseTime, err := timeParser(*inFormat, *tzIn)
 	if err != nil {
 		fatalf("%v", err)
 	}
+	if *replMode {
+		if flag.NArg() > 0 || *file != "" {
+			fatalf("cannot provide arguments or -f with -repl flag")
+		}
+		if err := runREPL(os.Stdin, os.Stdout); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
 	if *file != "" {
 		if flag.NArg() > 0 {
 			fatalf("cannot provide arguments with -file flag")
 		}
-		f := os.Stdin
-		if *file != "-" {
-			var err error
-			f, err = os.Open(*file)
-			if err != nil {
-				fatalf("%v, err")
-			}
+		f, err := openFile(*file)
+		if err != nil {
+			fatalf("%v", err)
 		}
-		for scanner := bufio.NewScanner(f); scanner.Scan(); {
-			t, err := parseTime(scanner.Text())
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "parse error on %q: %v\n", scanner.Text(), err)
-				continue
-			}
-			fmt.Printf("%s\n", formatTime(t))
+		win, err := newWindow()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if err := convertFile(f, os.Stdout, parseTime, formatTime, win); err != nil {
+			fatalf("%v", err)
 		}
 		return
 	}
-	var times []time.Time
 	args := flag.Args()
 	if len(args) == 0 {
 		args = []string{"now"}
 	}
 	if args[0] == "tz" {
-		printZones(args[1:])
+		if err := printZones(os.Stdout, args[1:]); err != nil {
+			fatalf("%v", err)
+		}
 		return
 	}
+	parseArg, err := argParser(*inFormat, *tzIn)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	results, err := evalArgs(args, parseArg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	for _, r := range results {
+		s, err := formatResult(r, formatTime)
+		if err != nil {
+			fatalf("cannot format time: %v", err)
+		}
+		fmt.Printf("%s\n", s)
+	}
+}
+
+// result holds a time and the format that it was parsed with.
+type result struct {
+	t      time.Time
+	format string
+	// zone holds the time zone to print the time in,
+	// if it's different from the -otz time zone.
+	zone string
+}
+
+// evalArgs evaluates the given arguments, each of which is a
+// time followed by any number of deltas, truncations and
+// @zone arguments, and returns the resulting times.
+// A @zone argument converts the time to the given
+// zone, which is also used to print it.
+func evalArgs(args []string, parseTime parseFunc) ([]result, error) {
+	var results []result
 	i := 0
 	for i < len(args) {
 		arg := args[i]
-		t, err := parseTime(arg)
+		t, tf, err := parseTime(arg)
 		if err != nil {
-			fatalf("parse error on %q: %v", arg, err)
+			return nil, fmt.Errorf("parse error on %q: %v", arg, err)
 		}
+		zone := ""
 		i++
 		for i < len(args) {
 			arg := args[i]
 			if arg != "" && (arg[0] == '-' || arg[0] == '+') {
-				d, err := parseDelta(arg)
+				d, err := timeparse.ParseDelta(arg)
 				if err != nil {
-					fatalf("parse error on duration %q: %v", arg, err)
+					return nil, fmt.Errorf("parse error on duration %q: %v", arg, err)
 				}
-				t = d.add(t)
+				t = d.Add(t)
+				i++
+			} else if isTruncation(arg) {
+				t, err = truncate(t, arg)
+				if err != nil {
+					return nil, err
+				}
+				i++
+			} else if len(arg) > 1 && arg[0] == '@' {
+				loc, err := loadLocation(arg[1:])
+				if err != nil {
+					return nil, err
+				}
+				t, zone = t.In(loc), arg[1:]
 				i++
 			} else {
 				break
 			}
 		}
-		times = append(times, t)
-	}
-	for _, t := range times {
-		fmt.Printf("%s\n", formatTime(t))
+		results = append(results, result{t, tf, zone})
 	}
+	return results, nil
 }
 
-type delta struct {
-	year, month, day int
-	duration         time.Duration
-}
-
-func parseDelta(s string) (delta, error) {
-	orig := s
-	dur, err := time.ParseDuration(s)
-	if err == nil {
-		return delta{
-			duration: dur,
-		}, nil
-	}
-	neg := false
-	if s != "" {
-		c := s[0]
-		if c == '-' || c == '+' {
-			neg = c == '-'
-			s = s[1:]
-		}
-	}
-	if s == "" {
-		return delta{}, fmt.Errorf("invalid duration %q", orig)
-	}
-	var d delta
-	for s != "" {
-		var v int32
-		v, s, err = leadingInt(s)
+// formatResult formats r with formatTime, or
+// in its own time zone if it has one.
+func formatResult(r result, formatTime formatFunc) (string, error) {
+	if r.zone != "" {
+		var err error
+		formatTime, err = formatterFor(*outFormat, r.zone)
 		if err != nil {
-			return delta{}, fmt.Errorf("invalid duration %q", orig)
-		}
-		if neg {
-			v = -v
-		}
-		i := 0
-		for ; i < len(s); i++ {
-			c := s[i]
-			if c == '.' || '0' <= c && c <= '9' {
-				break
-			}
-		}
-		if i == 0 {
-			return delta{}, fmt.Errorf("missing unit in duration %q", orig)
-		}
-		u := s[:i]
-		s = s[i:]
-		switch u {
-		case "y", "year", "years":
-			d.year += int(v)
-		case "mo", "month", "months":
-			d.month += int(v)
-		case "d", "day", "days":
-			d.day += int(v)
-		case "w", "week", "weeks":
-			d.day += 7 * int(v)
-		default:
-			return delta{}, fmt.Errorf("time unknown unit in duration %q", orig)
+			return "", err
 		}
 	}
-	return d, nil
+	return formatTime(r.t, r.format)
 }
 
-func (d delta) add(t time.Time) time.Time {
-	if d.duration != 0 {
-		return t.Add(d.duration)
+// timeParser returns a function that parses times in the given
+// format, interpreting them in the given time zone location.
+func timeParser(inFormat, inZone string) (parseFunc, error) {
+	p, err := zonedParser(inFormat, inZone)
+	if err != nil {
+		return nil, err
 	}
-	return t.AddDate(d.year, d.month, d.day)
+	return p.Parse, nil
 }
 
-// leadingInt consumes the leading [0-9]* from s.
-func leadingInt(s string) (x int32, rem string, err error) {
-	i := 0
-	for ; i < len(s); i++ {
-		c := s[i]
-		if c < '0' || c > '9' {
-			break
-		}
-		if x > (1<<31-1)/10 {
-			// overflow
-			return 0, "", errLeadingInt
-		}
-		x = x*10 + int32(c) - '0'
-		if x < 0 {
-			// overflow
-			return 0, "", errLeadingInt
-		}
+// argParser is like timeParser except that the times may carry
+// their own time zones, as time arguments can. It's not used for
+// input files because trying the last word of every line that
+// fails to parse as a time zone is slow.
+func argParser(inFormat, inZone string) (parseFunc, error) {
+	p, err := zonedParser(inFormat, inZone)
+	if err != nil {
+		return nil, err
 	}
-	return x, s[i:], nil
+	p.InlineZones = true
+	return p.Parse, nil
 }
 
-var errLeadingInt = errors.New("bad [0-9]*") // never printed
-
-func timeParser() (func(s string) (time.Time, error), error) {
-	tz, err := loadLocation(*tzIn)
+// zonedParser returns a parser for times in the given format,
+// interpreting them in the given time zone location.
+func zonedParser(inFormat, inZone string) (*timeparse.Parser, error) {
+	p, err := newParser(inFormat)
 	if err != nil {
 		return nil, err
 	}
-	if tz == nil {
-		tz = time.Local
-	}
-	now := time.Now().In(tz)
-	format := *inFormat
-	var parser func(s string) (time.Time, error)
-	if format1, ok := knownFormats[strings.ToLower(format)]; ok {
-		if format1 == "custom" {
-			parser = func(s string) (time.Time, error) {
-				return parseCustom(format, s, tz, now)
-			}
-		} else {
-			format = format1
-		}
-	}
-	if parser == nil {
-		components := timeformat.LayoutComponents(format)
-		now := time.Now()
-		parser = func(s string) (time.Time, error) {
-			t, err := time.ParseInLocation(format, s, tz)
-			if err != nil || *abs {
-				return t, err
-			}
-			return relativeTime(t, components, now), nil
-		}
+	p.Location, err = loadLocation(inZone)
+	if err != nil {
+		return nil, err
 	}
-	return func(s string) (time.Time, error) {
-		if s == "now" {
-			return now, nil
-		}
-		return parser(s)
-	}, nil
-}
-
-var componentsBySignificance = []timeformat.Components{
-	timeformat.Year,
-	timeformat.Month,
-	timeformat.Day,
-	timeformat.Hour,
-	timeformat.Minute,
-	timeformat.Second,
+	return p, nil
 }
 
-func relativeTime(t time.Time, components timeformat.Components, now time.Time) time.Time {
-	td := timeformat.TimeDate(t)
-	nowd := timeformat.TimeDate(now)
-	var toSet timeformat.Components
-	for _, c := range componentsBySignificance {
-		if components&c != 0 {
-			break
-		}
-		toSet |= c
+// timePattern returns a regular expression that matches text
+// that might be parsed as a time in the given format.
+func timePattern(format string) (*regexp.Regexp, error) {
+	p, err := newParser(format)
+	if err != nil {
+		return nil, err
 	}
-	td.SetComponents(nowd, toSet)
-	return td.Time()
+	return p.Pattern()
 }
 
-func parseCustom(format, s string, tz *time.Location, now time.Time) (time.Time, error) {
-	if format == "any" {
-		return parseAny(s, tz, now)
+// newParser returns a parser for times in the given format,
+// configured from the flags and the configuration file.
+func newParser(format string) (*timeparse.Parser, error) {
+	if strings.ToLower(format) == "json" {
+		return nil, fmt.Errorf("the json format can only be used for output")
 	}
-	ts, err := strconv.ParseInt(s, 10, 64)
+	style, err := textStyle(*calIn)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("unix?: %v", err)
-	}
-	switch format {
-	case "unix":
-		return time.Unix(ts, 0).In(tz), nil
-	case "unixnano":
-		return time.Unix(0, ts).In(tz), nil
-	case "unixmicro":
-		return time.Unix(ts/1e6, (ts%1e6)*1e3).In(tz), nil
-	case "unixmilli":
-		return time.Unix(ts/1e3, (ts%1e3)*1e6).In(tz), nil
-	default:
-		panic("unknown unix time format")
+		return nil, err
 	}
-}
-
-var anyFormats = []string{
-	knownFormats["git"],
-	time.RFC3339,
-	knownFormats["unixdate"],
-	"2006",
-	"2006-01-02",
-	"2006-01-02 15:04:05Z",
-	"2006-01-02T15:04:05",
-	"2006-01-02 15:04:05",
-	"01-02 15:04",
-	"Jan 1",
-	"Jan 1 15:04",
-	"Jan 1 15:04:05",
-	"1 Jan",
-	"1 Jan 15:04",
-	"1 Jan 15:04:05",
-	"15:04",
-	"15:04:05",
-	"3pm",
-	"3PM",
-	"3:04pm",
-	"3:04PM",
-	"3:04:05pm",
-	"3:04:05PM",
-}
-
-var unixFormats = []string{"unixnano", "unixmicro", "unixmilli", "unix"}
-
-func parseAny(s string, tz *time.Location, now time.Time) (time.Time, error) {
-	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
-		for _, format := range unixFormats {
-			t, err := parseCustom(format, s, tz, now)
-			if err != nil {
-				continue
-			}
-			if format == "unix" {
-				return t, nil
-			}
-			tooSmall := t.Year() == 1970 && t.Month() == time.January ||
-				t.Year() == 1969 && t.Month() != time.December
-			if !tooSmall {
-				return t, nil
-			}
-		}
+	policy, err := timeparse.ParseOffsetPolicy(*ixdtfPolicy)
+	if err != nil {
+		return nil, err
 	}
-	for _, format := range anyFormats {
-		t, err := time.ParseInLocation(format, s, tz)
-		if err != nil {
-			continue
-		}
-		return relativeTime(t, timeformat.LayoutComponents(format), now), nil
+	p := &timeparse.Parser{
+		Format:       lookupUserFormat(format),
+		Now:          time.Now(),
+		Abs:          *abs,
+		Style:        style,
+		AnyLayouts:   userConfig.Any,
+		Serial:       *anySerial,
+		OffsetPolicy: policy,
+		LoadLocation: func(name string) (*time.Location, error) {
+			return loadLocation(name)
+		},
+	}
+	if err := p.Check(); err != nil {
+		return nil, err
 	}
-	return time.Time{}, fmt.Errorf("cannot parse %q as arbitrary format", s)
+	return p, nil
+}
+
+// formatter returns a function that formats times
+// as specified by the -o and -otz flags.
+func formatter() (formatFunc, error) {
+	return formatterFor(*outFormat, *tzOut)
 }
 
-func formatter() (func(time.Time) string, error) {
-	tz, err := loadLocation(*tzOut)
+// formatterFor returns a function that formats times in the
+// given format, converted to the given time zone location.
+func formatterFor(outFormat, outZone string) (formatFunc, error) {
+	tz, err := loadLocation(outZone)
 	if err != nil {
 		return nil, err
 	}
@@ -370,116 +339,74 @@ func formatter() (func(time.Time) string, error) {
 		}
 		return t.In(tz)
 	}
-	format := *outFormat
-	if format1, ok := knownFormats[strings.ToLower(format)]; ok {
-		if format1 == "custom" {
-			return func(t time.Time) string {
-				return formatCustom(toTZ(t), format)
-			}, nil
-		}
-		format = format1
-	}
-	return func(t time.Time) string {
-		return toTZ(t).Format(format)
-	}, nil
-}
-
-func loadLocation(loc string) (*time.Location, error) {
-	switch strings.ToLower(loc) {
-	case "local":
-		return time.Local, nil
-	case "utc":
-		return time.UTC, nil
-	case "":
-		if *utc {
-			return time.UTC, nil
-		}
-		return nil, nil
+	style, err := textStyle(*calOut)
+	if err != nil {
+		return nil, err
 	}
-	tz, err := time.LoadLocation(loc)
-	if err == nil {
-		return tz, nil
-	}
-	available := zoneMatch(loc)
-	if len(available) > 1 {
-		// If the zones are actually all referring to the same underlying time zone, then
-		// allow it (for example, "samoa" could match both "US/Samoa" and "Pacific/Samoa"
-		// but they're actually both the same)
-		if !allIdenticalZones(available) {
-			return nil, fmt.Errorf("ambiguous time zone %q (%d matches; use 'godate tz %s' to see them)", loc, len(available), loc)
-		}
+	if strings.ToLower(outFormat) == "json" {
+		return func(t time.Time, parsedFormat string) (string, error) {
+			return formatJSON(toTZ(t), parsedFormat)
+		}, nil
 	}
-	if len(available) == 0 {
+	format := lookupUserFormat(outFormat)
+	if _, _, err := timeparse.LookupFormat(format); err != nil {
 		return nil, err
 	}
-	tz, err = time.LoadLocation(available[0])
-	if err != nil {
-		return nil, fmt.Errorf("time zone %s not available in system time zone database: %v", available[0], err)
-	}
-	return tz, nil
+	return func(t time.Time, _ string) (string, error) {
+		return timeparse.Format(toTZ(t), format, style)
+	}, nil
 }
 
-func formatCustom(t time.Time, format string) string {
-	switch format {
-	case "unix":
-		return fmt.Sprint(t.Unix())
-	case "unixmilli":
-		return fmt.Sprint(int64(time.Duration(t.UnixNano()) / time.Millisecond))
-	case "unixmicro":
-		return fmt.Sprint(int64(time.Duration(t.UnixNano()) / time.Microsecond))
-	case "unixnano":
-		return fmt.Sprint(t.UnixNano())
-	case "any":
-		// Arbitrary.
-		return t.Format(time.RFC3339)
-	default:
-		panic("unknown unix time format")
+// textStyle returns the style for the -locale flag
+// and the given calendar.
+func textStyle(calendar string) (timeformat.Style, error) {
+	locale, err := timeformat.LookupLocale(*localeName)
+	if err != nil {
+		return timeformat.Style{}, err
 	}
-}
-
-func zoneMatch(tz string) []string {
-	if _, ok := zoneNames[tz]; ok {
-		return []string{tz}
-	}
-	var matches []string
-	for name := range zoneNames {
-		if strings.EqualFold(name, tz) {
-			matches = append(matches, name)
-		}
+	start, err := fiscalStart()
+	if err != nil {
+		return timeformat.Style{}, err
 	}
-	if len(matches) > 0 {
-		return matches
+	style := timeformat.Style{
+		Locale:      locale,
+		FiscalStart: start,
 	}
-	tz = strings.ToLower(tz)
-	for name := range zoneNames {
-		if strings.Contains(strings.ToLower(name), tz) {
-			matches = append(matches, name)
+	if calendar != "" {
+		style.Calendar, err = timeformat.LookupCalendar(calendar)
+		if err != nil {
+			return timeformat.Style{}, err
 		}
 	}
-	return matches
+	return style, nil
 }
 
-func allIdenticalZones(tzs []string) bool {
-	if len(tzs) < 2 {
-		return true
-	}
-	ctz := canonicalTimezone(tzs[0])
-	for _, tz := range tzs[1:] {
-		if canonicalTimezone(tz) != ctz {
-			return false
+// loadLocation returns the time zone with the given name, which may be
+// an alias from the configuration file. The empty name means UTC
+// with the -u flag, or no particular time zone otherwise, in which
+// case it returns nil.
+func loadLocation(loc string) (*time.Location, error) {
+	loc = zoneAlias(loc)
+	if loc == "" {
+		if *utc {
+			return time.UTC, nil
 		}
+		return nil, nil
 	}
-	return true
+	tz, err := timeparse.LoadLocation(loc)
+	if err, ok := err.(*timeparse.AmbiguousZoneError); ok {
+		return nil, fmt.Errorf("ambiguous time zone %q (%d matches; use 'godate tz %s' to see them)", loc, len(err.Matches), loc)
+	}
+	return tz, err
 }
 
-func canonicalTimezone(tz string) string {
-	for {
-		link := zoneNames[tz]
-		if link == "" {
-			return tz
-		}
-		tz = link
+// openFile opens the named file for reading;
+// the name "-" means standard input.
+func openFile(name string) (*os.File, error) {
+	if name == "-" {
+		return os.Stdin, nil
 	}
+	return os.Open(name)
 }
 
 func fatalf(f string, a ...interface{}) {
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// jsonObject holds a JSON object, preserving
+// the order of its members.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// pathStep holds one step in a JSON path.
+type pathStep struct {
+	// key holds the object member to select.
+	key string
+	// index holds the array element to select,
+	// or -1 to select all elements. It's ignored
+	// when key is non-empty.
+	index int
+}
+
+// jsonPath holds a path selecting values for conversion.
+type jsonPath struct {
+	steps     []pathStep
+	parseTime parseFunc
+}
+
+// parseJSONPath parses a path of the form path[=format][@zone].
+// The path is a sequence of steps, each either .key to select
+// an object member, [] to select all elements of an array,
+// or [n] to select a single element. The path "." selects
+// the top level value.
+func parseJSONPath(spec string) (*jsonPath, error) {
+	sel, parseTime, err := parseSelector(spec)
+	if err != nil {
+		return nil, err
+	}
+	p := &jsonPath{
+		parseTime: parseTime,
+	}
+	if sel == "." {
+		return p, nil
+	}
+	s := sel
+	for s != "" {
+		switch s[0] {
+		case '.':
+			i := strings.IndexAny(s[1:], ".[") + 1
+			if i == 0 {
+				i = len(s)
+			}
+			if i == 1 {
+				return nil, fmt.Errorf("empty key in JSON path %q", sel)
+			}
+			p.steps = append(p.steps, pathStep{key: s[1:i]})
+			s = s[i:]
+		case '[':
+			i := strings.Index(s, "]")
+			if i == -1 {
+				return nil, fmt.Errorf("unterminated [ in JSON path %q", sel)
+			}
+			step := pathStep{index: -1}
+			if i > 1 {
+				n, err := strconv.Atoi(s[1:i])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("bad index in JSON path %q", sel)
+				}
+				step.index = n
+			}
+			p.steps = append(p.steps, step)
+			s = s[i+1:]
+		default:
+			return nil, fmt.Errorf("JSON path %q must start with . or [", sel)
+		}
+	}
+	return p, nil
+}
+
+// convertJSON reads a stream of JSON values from r and writes them to w,
+// one per line, with the times at the paths specified by specs reformatted
+// by formatTime. Values at those paths that don't parse as times are
+// left alone. Only values where the first time found is inside the window
+// are written.
+func convertJSON(r io.Reader, w io.Writer, specs []string, formatTime formatFunc, win *window) error {
+	paths := make([]*jsonPath, len(specs))
+	for i, spec := range specs {
+		p, err := parseJSONPath(spec)
+		if err != nil {
+			return err
+		}
+		paths[i] = p
+	}
+	// toJSON converts a formatted time to the JSON value written.
+	toJSON := func(s string) interface{} {
+		return s
+	}
+	switch {
+	case strings.ToLower(*outFormat) == "json":
+		// The json format prints a JSON object.
+		toJSON = func(s string) interface{} {
+			return json.RawMessage(s)
+		}
+	case *jsonNum && timeparse.IsNumeric(lookupUserFormat(*outFormat)):
+		// With -jsonnum, times are written as JSON numbers only when
+		// the output format always prints numbers, so that layouts
+		// such as 0102 that happen to print digits stay strings.
+		toJSON = func(s string) interface{} {
+			return json.Number(s)
+		}
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	var buf bytes.Buffer
+	for {
+		v, err := readJSON(dec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var first time.Time
+		found := false
+		for _, p := range paths {
+			v = convertPath(v, p.steps, func(v interface{}) interface{} {
+				t, tf, ok := parseJSONTime(v, p.parseTime)
+				if !ok {
+					return v
+				}
+				if !found {
+					first, found = t, true
+				}
+				if *orig {
+					return v
+				}
+				return formatJSONTime(v, t, tf, formatTime, toJSON)
+			})
+		}
+		if !win.include(first, found) {
+			continue
+		}
+		buf.Reset()
+		writeJSON(&buf, v)
+		buf.WriteByte('\n')
+		if _, err := bw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// convertPath calls convert on all the values selected by
+// steps in v and returns v with those values replaced.
+func convertPath(v interface{}, steps []pathStep, convert func(interface{}) interface{}) interface{} {
+	if len(steps) == 0 {
+		return convert(v)
+	}
+	step := steps[0]
+	switch v := v.(type) {
+	case jsonObject:
+		if step.key == "" {
+			return v
+		}
+		for i, m := range v {
+			if m.key == step.key {
+				v[i].value = convertPath(m.value, steps[1:], convert)
+			}
+		}
+	case []interface{}:
+		if step.key != "" {
+			return v
+		}
+		for i, elem := range v {
+			if step.index == -1 || step.index == i {
+				v[i] = convertPath(elem, steps[1:], convert)
+			}
+		}
+	}
+	return v
+}
+
+// parseJSONTime parses the JSON string or number v as a time.
+func parseJSONTime(v interface{}, parseTime parseFunc) (time.Time, string, bool) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = string(v)
+	default:
+		return time.Time{}, "", false
+	}
+	t, tf, err := parseTime(s)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t, tf, true
+}
+
+// formatJSONTime returns the JSON value to replace v, which was parsed
+// as t with the format tf. The formatted time is converted to a JSON
+// value by toJSON. If the time can't be formatted, the error is
+// reported and v is returned unchanged.
+func formatJSONTime(v interface{}, t time.Time, tf string, formatTime formatFunc, toJSON func(string) interface{}) interface{} {
+	out, err := formatTime(t, tf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot format %q: %v\n", v, err)
+		return v
+	}
+	return toJSON(out)
+}
+
+// readJSON reads a JSON value from dec. Objects are returned as jsonObject,
+// arrays as []interface{} and numbers as json.Number.
+func readJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			v, err := readJSON(dec)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			obj = append(obj, jsonMember{key.(string), v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			v, err := readJSON(dec)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			arr = append(arr, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return arr, nil
+	}
+	return tok, nil
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF if err is io.EOF,
+// which means that the input ended inside a JSON value.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// writeJSON writes v as compact JSON to buf. Unlike json.Marshal, it
+// preserves object member order and doesn't escape HTML characters.
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case jsonObject:
+		buf.WriteByte('{')
+		for i, m := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, m.key)
+			buf.WriteByte(':')
+			writeJSON(buf, m.value)
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, elem)
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		buf.WriteString(string(v))
+	case json.RawMessage:
+		buf.Write(v)
+	default:
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(v)
+		// Remove the newline added by Encode.
+		buf.Truncate(buf.Len() - 1)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConvertJSONDescribe(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(outFormat, "json")
+	formatTime, err := formatterFor("json", "UTC")
+	c.Assert(err, qt.IsNil)
+	var buf bytes.Buffer
+	err = convertJSON(strings.NewReader(`{"t":"1700000000"}`), &buf, []string{".t=unix@UTC"}, formatTime, &window{keep: true})
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Matches, `\{"t":\{"rfc3339utc":"2023-11-14T22:13:20Z",.*"format":"unix"\}\}\n`)
+}
+
+var convertJSONTests = []struct {
+	testName    string
+	specs       []string
+	outFormat   string
+	jsonNum     bool
+	input       string
+	expect      string
+	expectError string
+}{{
+	testName: "top-level-member",
+	specs:    []string{".ts=unix"},
+	input:    `{"ts":1700000000,"msg":"<hi>"}`,
+	expect:   `{"ts":"2023-11-14T22:13:20Z","msg":"<hi>"}` + "\n",
+}, {
+	testName: "ndjson",
+	specs:    []string{".ts"},
+	input:    "{\"ts\":\"2024-01-02T03:04:05+01:00\"}\n{\"ts\":\"not a time\"}\n{\"other\":1}\n",
+	expect:   "{\"ts\":\"2024-01-02T02:04:05Z\"}\n{\"ts\":\"not a time\"}\n{\"other\":1}\n",
+}, {
+	testName: "nested",
+	specs:    []string{".a.b.c=unixmilli"},
+	input:    `{"a":{"b":{"c":1700000000000,"d":1700000000000}},"c":1700000000000}`,
+	expect:   `{"a":{"b":{"c":"2023-11-14T22:13:20Z","d":1700000000000}},"c":1700000000000}` + "\n",
+}, {
+	testName: "all-array-elements",
+	specs:    []string{".spans[].start=unix"},
+	input:    `{"spans":[{"start":1700000000},{"start":"1700000060"},{"end":1}]}`,
+	expect:   `{"spans":[{"start":"2023-11-14T22:13:20Z"},{"start":"2023-11-14T22:14:20Z"},{"end":1}]}` + "\n",
+}, {
+	testName: "one-array-element",
+	specs:    []string{"[1]=unix"},
+	input:    `[1700000000,1700000000]`,
+	expect:   `[1700000000,"2023-11-14T22:13:20Z"]` + "\n",
+}, {
+	testName: "whole-value",
+	specs:    []string{".=unix"},
+	input:    "1700000000 1700000060",
+	expect:   "\"2023-11-14T22:13:20Z\"\n\"2023-11-14T22:14:20Z\"\n",
+}, {
+	testName:  "numeric-output-as-string",
+	specs:     []string{".ts"},
+	outFormat: "unixmilli",
+	input:     `{"ts":"2023-11-14T22:13:20Z"}`,
+	expect:    `{"ts":"1700000000000"}` + "\n",
+}, {
+	testName:  "jsonnum",
+	specs:     []string{".ts"},
+	outFormat: "unixmilli",
+	jsonNum:   true,
+	input:     `{"ts":"2023-11-14T22:13:20Z"}`,
+	expect:    `{"ts":1700000000000}` + "\n",
+}, {
+	testName:  "jsonnum-layout",
+	specs:     []string{".ts"},
+	outFormat: "0102",
+	jsonNum:   true,
+	input:     `{"ts":"2023-11-14T22:13:20Z"}`,
+	expect:    `{"ts":"1114"}` + "\n",
+}, {
+	testName:    "bad-path",
+	specs:       []string{"ts"},
+	input:       `{}`,
+	expectError: `JSON path "ts" must start with . or \[`,
+}, {
+	testName:    "bad-index",
+	specs:       []string{".a[x]"},
+	input:       `{}`,
+	expectError: `bad index in JSON path ".a\[x\]"`,
+}, {
+	testName:    "bad-json",
+	specs:       []string{".a"},
+	input:       `{"a":`,
+	expectError: `unexpected EOF`,
+}}
+
+func TestConvertJSON(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(inFormat, "any")
+	c.Patch(tzIn, "UTC")
+	for _, test := range convertJSONTests {
+		c.Run(test.testName, func(c *qt.C) {
+			out := test.outFormat
+			if out == "" {
+				out = "rfc3339"
+			}
+			c.Patch(outFormat, out)
+			c.Patch(jsonNum, test.jsonNum)
+			formatTime, err := formatterFor(out, "UTC")
+			c.Assert(err, qt.IsNil)
+			var buf bytes.Buffer
+			err = convertJSON(strings.NewReader(test.input), &buf, test.specs, formatTime, &window{keep: true})
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// lineEditor reads lines typed at a terminal, with
+// history and tab completion.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	prompt  string
+	restore func()
+
+	// complete returns the line with its last word completed
+	// and the possible completions.
+	complete func(line string) (string, []string)
+
+	history []string
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// newLineEditor returns a line editor that reads from the terminal in,
+// which is switched to a mode where characters are read as
+// they're typed. The close method must be called to restore
+// the original mode. The mode is also restored if the process
+// is terminated or the terminal hangs up while the editor is open.
+// The terminal mode is changed with the stty command, so newLineEditor
+// fails if that isn't available.
+func newLineEditor(in *os.File, out io.Writer, prompt string, complete func(string) (string, []string)) (*lineEditor, error) {
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get terminal mode: %v", err)
+	}
+	restoreMode := func() {
+		stty(in, strings.TrimSpace(saved))
+	}
+	if _, err := stty(in, "-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		restoreMode()
+		return nil, fmt.Errorf("cannot set terminal mode: %v", err)
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGTERM, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigc:
+			restoreMode()
+			os.Exit(128 + int(sig.(syscall.Signal)))
+		case <-done:
+		}
+	}()
+	return &lineEditor{
+		in:     bufio.NewReader(in),
+		out:    out,
+		prompt: prompt,
+		restore: func() {
+			signal.Stop(sigc)
+			close(done)
+			restoreMode()
+		},
+		complete: complete,
+	}, nil
+}
+
+// stty runs the stty command on the terminal f.
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// close restores the original terminal mode.
+func (e *lineEditor) close() {
+	e.restore()
+}
+
+// Control characters understood by readLine.
+const (
+	ctrlC     = 3
+	ctrlD     = 4
+	backspace = 8
+	tab       = 9
+	ctrlU     = 21
+	escape    = 27
+	del       = 127
+)
+
+// readLine reads a line. It returns io.EOF when
+// control-D is typed on an empty line.
+func (e *lineEditor) readLine() (string, error) {
+	var line []byte
+	// hist holds the index of the history entry being shown.
+	hist := len(e.history)
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r\x1b[K%s%s", e.prompt, line)
+	}
+	redraw()
+	for {
+		c, err := e.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\n")
+			if len(line) > 0 {
+				e.history = append(e.history, string(line))
+			}
+			return string(line), nil
+		case ctrlD:
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+		case ctrlC:
+			fmt.Fprint(e.out, "^C\n")
+			line = line[:0]
+			hist = len(e.history)
+			redraw()
+		case ctrlU:
+			line = line[:0]
+			redraw()
+		case backspace, del:
+			if len(line) > 0 {
+				_, n := utf8.DecodeLastRune(line)
+				line = line[:len(line)-n]
+				redraw()
+			}
+		case tab:
+			completed, cands := e.complete(string(line))
+			if completed != string(line) {
+				line = []byte(completed)
+			} else if len(cands) > 1 {
+				fmt.Fprintf(e.out, "\n%s\n", strings.Join(cands, "  "))
+			}
+			redraw()
+		case escape:
+			// Only the up and down arrow keys are supported;
+			// other escape sequences are ignored.
+			switch e.readEscape() {
+			case "[A", "OA":
+				if hist > 0 {
+					hist--
+					line = []byte(e.history[hist])
+				}
+			case "[B", "OB":
+				if hist < len(e.history) {
+					hist++
+					line = line[:0]
+					if hist < len(e.history) {
+						line = []byte(e.history[hist])
+					}
+				}
+			}
+			redraw()
+		default:
+			if c >= ' ' {
+				line = append(line, c)
+				// Write the byte as it is, so that the terminal
+				// sees multi-byte characters as they were typed.
+				e.out.Write([]byte{c})
+			}
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence
+// after the escape character.
+func (e *lineEditor) readEscape() string {
+	var seq []byte
+	for {
+		c, err := e.in.ReadByte()
+		if err != nil {
+			return string(seq)
+		}
+		seq = append(seq, c)
+		// The sequence ends with a letter or tilde, after
+		// the introducer ([ or O).
+		if len(seq) > 1 && (c >= '@' && c <= '~') || len(seq) == 1 && c != '[' && c != 'O' {
+			return string(seq)
+		}
+	}
+}
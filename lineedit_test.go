@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// newTestLineEditor returns a line editor that reads
+// the given input and writes to out.
+func newTestLineEditor(input string, out io.Writer) *lineEditor {
+	return &lineEditor{
+		in:       bufio.NewReader(strings.NewReader(input)),
+		out:      out,
+		prompt:   "> ",
+		restore:  func() {},
+		complete: completeLine,
+	}
+}
+
+var readLineTests = []struct {
+	testName   string
+	input      string
+	expect     []string
+	expectEcho string
+}{{
+	testName: "lines",
+	input:    "now\r+1h\n",
+	expect:   []string{"now", "+1h"},
+}, {
+	// Multi-byte characters are echoed as typed.
+	testName:   "utf8",
+	input:      "5 févr.\r",
+	expect:     []string{"5 févr."},
+	expectEcho: "\r\x1b[K> 5 févr.\n",
+}, {
+	testName: "backspace",
+	input:    "été\x7f\x7fx\b\rab\x15cd\r",
+	expect:   []string{"é", "cd"},
+}, {
+	testName: "control-c",
+	input:    "now\x03later\r",
+	expect:   []string{"later"},
+}, {
+	testName: "tab",
+	input:    "tz lond\t\r",
+	expect:   []string{"tz Europe/London "},
+}, {
+	testName: "history",
+	input:    "a\rb\r\x1b[A\x1b[A\x1b[B\r\x1bOA\r\x1b[C\r",
+	expect:   []string{"a", "b", "b", "b", ""},
+}, {
+	testName: "control-d",
+	input:    "a\x04\r\x04",
+	expect:   []string{"a"},
+}}
+
+func TestReadLine(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range readLineTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var out bytes.Buffer
+			e := newTestLineEditor(test.input, &out)
+			var lines []string
+			for {
+				line, err := e.readLine()
+				if err == io.EOF {
+					break
+				}
+				c.Assert(err, qt.IsNil)
+				lines = append(lines, line)
+			}
+			c.Assert(lines, qt.DeepEquals, test.expect)
+			if test.expectEcho != "" {
+				c.Assert(strings.HasPrefix(out.String(), test.expectEcho), qt.IsTrue, qt.Commentf("output %q", out.String()))
+			}
+		})
+	}
+}
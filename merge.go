@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+)
+
+// mergeSource holds one of the files being merged.
+type mergeSource struct {
+	name      string
+	index     int
+	scanner   *bufio.Scanner
+	pat       *regexp.Regexp
+	parseTime parseFunc
+
+	// t and lines hold the current entry: a line holding
+	// a time followed by any lines without a time.
+	t     time.Time
+	lines []string
+
+	// look holds the line that starts the next entry
+	// and lookTime its time, when haveLook is true.
+	look     string
+	lookTime time.Time
+	haveLook bool
+}
+
+// next reads the next entry from the source and
+// reports whether there is one. Lines at the start of
+// the file without a time form an entry with the zero time.
+func (src *mergeSource) next() bool {
+	src.lines = src.lines[:0]
+	src.t = time.Time{}
+	if src.haveLook {
+		src.t = src.lookTime
+		src.lines = append(src.lines, src.look)
+		src.haveLook = false
+	}
+	for src.scanner.Scan() {
+		line := src.scanner.Text()
+		t, ok := findTime(line, src.pat, src.parseTime)
+		if ok && len(src.lines) > 0 {
+			src.look, src.lookTime, src.haveLook = line, t, true
+			return true
+		}
+		if ok {
+			src.t = t
+		}
+		src.lines = append(src.lines, line)
+	}
+	return len(src.lines) > 0
+}
+
+// mergeHeap implements heap.Interface, ordering sources
+// by the time of their current entry. Entries with the same
+// time are ordered by the order of the files on the command line.
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if !h[i].t.Equal(h[j].t) {
+		return h[i].t.Before(h[j].t)
+	}
+	return h[i].index < h[j].index
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) {
+	*h = append(*h, x.(*mergeSource))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	src := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return src
+}
+
+// mergeFiles writes the entries from the sources to w in time order.
+// If prefix is true, each entry is prefixed by its time as
+// formatted by formatTime.
+func mergeFiles(w io.Writer, srcs []*mergeSource, formatTime formatFunc, prefix bool) error {
+	bw := bufio.NewWriter(w)
+	h := make(mergeHeap, 0, len(srcs))
+	for _, src := range srcs {
+		if src.next() {
+			h = append(h, src)
+		} else if err := src.scanner.Err(); err != nil {
+			return fmt.Errorf("%s: %v", src.name, err)
+		}
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		src := h[0]
+		for i, line := range src.lines {
+			if i == 0 && prefix && !src.t.IsZero() {
+				s, err := formatTime(src.t, "")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(bw, "%s ", s)
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+		if src.next() {
+			heap.Fix(&h, 0)
+			continue
+		}
+		if err := src.scanner.Err(); err != nil {
+			return fmt.Errorf("%s: %v", src.name, err)
+		}
+		heap.Pop(&h)
+	}
+	return bw.Flush()
+}
+
+// runMerge runs the merge subcommand, which merges the
+// files named in args in time order. Each argument is
+// of the form file[=format][@zone]; flags may precede the
+// file names.
+func runMerge(args []string) {
+	if err := flag.CommandLine.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	applyConfig()
+	if flag.NArg() == 0 {
+		fatalf("no files to merge")
+	}
+	formatTime, err := formatter()
+	if err != nil {
+		fatalf("%v", err)
+	}
+	var srcs []*mergeSource
+	for i, spec := range flag.Args() {
+		name, format, zone := splitSelector(spec)
+		parseTime, err := timeParser(format, zone)
+		if err != nil {
+			fatalf("bad file %q: %v", spec, err)
+		}
+		pat, err := timePattern(format)
+		if err != nil {
+			fatalf("bad file %q: %v", spec, err)
+		}
+		f, err := openFile(name)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		defer f.Close()
+		srcs = append(srcs, &mergeSource{
+			name:      name,
+			index:     i,
+			scanner:   bufio.NewScanner(f),
+			pat:       pat,
+			parseTime: parseTime,
+		})
+	}
+	if err := mergeFiles(os.Stdout, srcs, formatTime, *prefix); err != nil {
+		fatalf("%v", err)
+	}
+}
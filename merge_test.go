@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// mergeInputs holds two logs with different formats and time zones.
+// The entry at 09:00:00 in New York has the same time as b0 and
+// the entry at 09:00:02 has the same time as b2.
+var mergeInputs = []struct {
+	spec  string
+	input string
+}{{
+	spec: "a.log@America/New_York",
+	input: `
+2024-03-01 09:00:00 a1
+	continuation of a1
+2024-03-01 09:00:02 a2
+`[1:],
+}, {
+	spec: "b.log=unixmilli@UTC",
+	input: `
+header without a time
+1709301600000 b0
+1709301601000 b1
+1709301602000 b2
+`[1:],
+}}
+
+var mergeTests = []struct {
+	testName string
+	prefix   bool
+	reverse  bool
+	expect   string
+}{{
+	testName: "plain",
+	expect: `
+header without a time
+2024-03-01 09:00:00 a1
+	continuation of a1
+1709301600000 b0
+1709301601000 b1
+2024-03-01 09:00:02 a2
+1709301602000 b2
+`[1:],
+}, {
+	testName: "prefix",
+	prefix:   true,
+	expect: `
+header without a time
+2024-03-01T14:00:00Z 2024-03-01 09:00:00 a1
+	continuation of a1
+2024-03-01T14:00:00Z 1709301600000 b0
+2024-03-01T14:00:01Z 1709301601000 b1
+2024-03-01T14:00:02Z 2024-03-01 09:00:02 a2
+2024-03-01T14:00:02Z 1709301602000 b2
+`[1:],
+}, {
+	// Entries with the same time are written in
+	// the order of the files on the command line.
+	testName: "reverse",
+	reverse:  true,
+	expect: `
+header without a time
+1709301600000 b0
+2024-03-01 09:00:00 a1
+	continuation of a1
+1709301601000 b1
+1709301602000 b2
+2024-03-01 09:00:02 a2
+`[1:],
+}}
+
+func TestMergeFiles(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(inFormat, "any")
+	c.Patch(tzIn, "UTC")
+	formatTime, err := formatterFor("rfc3339", "UTC")
+	c.Assert(err, qt.IsNil)
+	for _, test := range mergeTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var srcs []*mergeSource
+			for i := range mergeInputs {
+				in := mergeInputs[i]
+				if test.reverse {
+					in = mergeInputs[len(mergeInputs)-1-i]
+				}
+				name, format, zone := splitSelector(in.spec)
+				parseTime, err := timeParser(format, zone)
+				c.Assert(err, qt.IsNil)
+				pat, err := timePattern(format)
+				c.Assert(err, qt.IsNil)
+				srcs = append(srcs, &mergeSource{
+					name:      name,
+					index:     i,
+					scanner:   bufio.NewScanner(strings.NewReader(in.input)),
+					pat:       pat,
+					parseTime: parseTime,
+				})
+			}
+			var buf bytes.Buffer
+			err := mergeFiles(&buf, srcs, formatTime, test.prefix)
+			c.Assert(err, qt.IsNil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// replHelp is printed by the :help command.
+const replHelp = `Each line holds times followed by optional deltas and truncations,
+as on the command line, or "tz" followed by time zone names. Use quotes
+for times that contain spaces. The previous result is available as _.
+
+Commands:
+	:flag value	set a flag, for example :otz tokyo or :o kitchen
+	:flag		print the value of a flag
+	:help		print this message
+	:quit		leave (as does end of file)
+
+Tab completes time zone names, format names, calendars, locales
+and delta and truncation units.
+`
+
+// repl holds the state of an interactive session.
+type repl struct {
+	out io.Writer
+	// last holds the previous result, if any.
+	last *result
+}
+
+// runREPL runs an interactive session reading from in and
+// writing to out. When in is a terminal, it's read with a line
+// editor that supports history and tab completion if possible;
+// otherwise lines are read as they come.
+func runREPL(in *os.File, out io.Writer) error {
+	r := &repl{out: out}
+	prompt := ""
+	scanner := bufio.NewScanner(in)
+	readLine := func() (string, error) {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+	if isTerminal(in) {
+		e, err := newLineEditor(in, out, "godate> ", completeLine)
+		if err == nil {
+			defer e.close()
+			readLine = e.readLine
+		} else {
+			// The terminal mode can't be changed (there may
+			// be no stty command, or in may not really be
+			// a terminal), so let the terminal do the editing.
+			prompt = "godate> "
+		}
+	}
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		quit, err := r.run(line)
+		if err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// run runs one line of input and reports whether
+// the session should end.
+func (r *repl) run(line string) (quit bool, err error) {
+	words, err := splitWords(line)
+	if err != nil || len(words) == 0 {
+		return false, err
+	}
+	if strings.HasPrefix(words[0], ":") {
+		return r.command(words[0][1:], words[1:])
+	}
+	if words[0] == "tz" {
+		return false, printZones(r.out, words[1:])
+	}
+	formatTime, err := formatter()
+	if err != nil {
+		return false, err
+	}
+	parseTime, err := argParser(*inFormat, *tzIn)
+	if err != nil {
+		return false, err
+	}
+	results, err := evalArgs(words, func(s string) (time.Time, string, error) {
+		if s == "_" {
+			if r.last == nil {
+				return time.Time{}, "", fmt.Errorf("no previous result")
+			}
+			return r.last.t, r.last.format, nil
+		}
+		return parseTime(s)
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, res := range results {
+		s, err := formatResult(res, formatTime)
+		if err != nil {
+			return false, fmt.Errorf("cannot format time: %v", err)
+		}
+		fmt.Fprintf(r.out, "%s\n", s)
+	}
+	r.last = &results[len(results)-1]
+	return false, nil
+}
+
+// command runs the colon command with the given name and arguments.
+func (r *repl) command(name string, args []string) (quit bool, err error) {
+	switch name {
+	case "q", "quit", "exit":
+		return true, nil
+	case "h", "help":
+		fmt.Fprint(r.out, replHelp)
+		return false, nil
+	}
+	f := flag.Lookup(name)
+	if f == nil || !replFlags[name] {
+		return false, fmt.Errorf("unknown command :%s (try :help)", name)
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(r.out, "%s\n", f.Value)
+		return false, nil
+	}
+	old := f.Value.String()
+	if err := flag.Set(name, strings.Join(args, " ")); err != nil {
+		return false, err
+	}
+	// Check that the new value makes sense.
+	if _, err := formatter(); err != nil {
+		flag.Set(name, old)
+		return false, err
+	}
+	if _, err := timeParser(*inFormat, *tzIn); err != nil {
+		flag.Set(name, old)
+		return false, err
+	}
+	return false, nil
+}
+
+// replFlags holds the flags that can be changed during a session.
+var replFlags = map[string]bool{
+	"abs":    true,
+	"fiscal": true,
+	"i":      true,
+	"ical":   true,
+	"itz":    true,
+	"ixdtf":  true,
+	"locale": true,
+	"o":      true,
+	"ocal":   true,
+	"otz":    true,
+	"serial": true,
+	"u":      true,
+}
+
+// splitWords splits line into words separated by white space.
+// Single or double quotes can be used for words containing spaces.
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// completeLine returns line with its last word completed, and
+// the possible completions of the word.
+func completeLine(line string) (string, []string) {
+	i := strings.LastIndexAny(line, " \t") + 1
+	word := line[i:]
+	words := strings.Fields(line[:i])
+	cmd := ""
+	if len(words) > 0 {
+		cmd = words[0]
+	}
+	var cands []string
+	switch {
+	case len(words) == 0 && strings.HasPrefix(word, ":"):
+		names := []string{":help", ":quit"}
+		for name := range replFlags {
+			names = append(names, ":"+name)
+		}
+		cands = withPrefix(names, "", word)
+	case len(words) == 1 && strings.HasPrefix(cmd, ":"):
+		cands = completeFlagValue(cmd[1:], word)
+	case cmd == "tz":
+		cands = completeZone(word)
+	case !strings.HasPrefix(cmd, ":"):
+		extra := []string{"_"}
+		if len(words) == 0 {
+			extra = append(extra, "tz")
+		}
+		cands = append(completeTimeArg(word), withPrefix(extra, "", word)...)
+	}
+	sort.Strings(cands)
+	switch len(cands) {
+	case 0:
+		return line, nil
+	case 1:
+		if !strings.HasSuffix(cands[0], ":") {
+			return line[:i] + cands[0] + " ", cands
+		}
+		return line[:i] + cands[0], cands
+	}
+	if p := commonPrefix(cands); len(p) > len(word) && strings.HasPrefix(strings.ToLower(p), strings.ToLower(word)) {
+		return line[:i] + p, cands
+	}
+	return line, cands
+}
+
+// commonPrefix returns the longest common prefix of ss.
+func commonPrefix(ss []string) string {
+	p := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, p) {
+			p = p[:len(p)-1]
+		}
+	}
+	return p
+}
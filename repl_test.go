@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var splitWordsTests = []struct {
+	line        string
+	expect      []string
+	expectError string
+}{{
+	line:   "",
+	expect: nil,
+}, {
+	line:   "  now  +1h\ttrunc:d ",
+	expect: []string{"now", "+1h", "trunc:d"},
+}, {
+	line:   `'2024-03-01 10:00' +90m`,
+	expect: []string{"2024-03-01 10:00", "+90m"},
+}, {
+	line:   `"it's" 'say "hi"'`,
+	expect: []string{"it's", `say "hi"`},
+}, {
+	line:   `a'b c'd ''`,
+	expect: []string{"ab cd", ""},
+}, {
+	line:   "'été' 5",
+	expect: []string{"été", "5"},
+}, {
+	line:        `'2024-03-01 10:00`,
+	expectError: `unterminated quote`,
+}}
+
+func TestSplitWords(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range splitWordsTests {
+		words, err := splitWords(test.line)
+		if test.expectError != "" {
+			c.Check(err, qt.ErrorMatches, test.expectError, qt.Commentf("%q", test.line))
+			continue
+		}
+		c.Check(err, qt.IsNil)
+		c.Check(words, qt.DeepEquals, test.expect, qt.Commentf("%q", test.line))
+	}
+}
+
+// replSession holds a sequence of lines typed into a session,
+// with the output and error expected from each.
+var replSession = []struct {
+	line        string
+	expect      string
+	expectError string
+	expectQuit  bool
+}{{
+	line:        "_",
+	expectError: `parse error on "_": no previous result`,
+}, {
+	line:   "2024-03-01T10:00:00Z +90m",
+	expect: "2024-03-01T11:30:00Z\n",
+}, {
+	line: ":otz tokyo",
+}, {
+	line:   ":otz",
+	expect: "tokyo\n",
+}, {
+	line:   "_ trunc:d",
+	expect: "2024-03-01T09:00:00+09:00\n",
+}, {
+	line:   "'2024-03-01 10:00' 2024-03-01T09:00@tokyo '2024-03-01 09:00 Europe/Paris'",
+	expect: "2024-03-01T19:00:00+09:00\n2024-03-01T09:00:00+09:00\n2024-03-01T17:00:00+09:00\n",
+}, {
+	line:        ":otz nowhere",
+	expectError: `unknown time zone nowhere`,
+}, {
+	// The bad value has been rejected.
+	line:   ":otz",
+	expect: "tokyo\n",
+}, {
+	line:        ":alias",
+	expectError: `unknown command :alias \(try :help\)`,
+}, {
+	line:        "'2024-03-01",
+	expectError: `unterminated quote`,
+}, {
+	line: "",
+}, {
+	line:   "tz london",
+	expect: "Europe/London\n",
+}, {
+	line:       ":quit",
+	expectQuit: true,
+}}
+
+func TestREPLRun(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(inFormat, "any")
+	c.Patch(outFormat, "rfc3339")
+	c.Patch(tzIn, "UTC")
+	c.Patch(tzOut, "")
+	c.Patch(utc, false)
+	c.Patch(abs, true)
+	var buf bytes.Buffer
+	r := &repl{out: &buf}
+	for _, step := range replSession {
+		buf.Reset()
+		quit, err := r.run(step.line)
+		if step.expectError != "" {
+			c.Assert(err, qt.ErrorMatches, step.expectError, qt.Commentf("%q", step.line))
+		} else {
+			c.Assert(err, qt.IsNil, qt.Commentf("%q", step.line))
+		}
+		c.Assert(quit, qt.Equals, step.expectQuit, qt.Commentf("%q", step.line))
+		c.Assert(buf.String(), qt.Equals, step.expect, qt.Commentf("%q", step.line))
+	}
+}
+
+var completeLineTests = []struct {
+	line        string
+	expect      string
+	expectCands []string
+}{{
+	line:        ":he",
+	expect:      ":help ",
+	expectCands: []string{":help"},
+}, {
+	line:        ":o",
+	expect:      ":o",
+	expectCands: []string{":o", ":ocal", ":otz"},
+}, {
+	line:        ":otz tok",
+	expect:      ":otz Asia/Tokyo ",
+	expectCands: []string{"Asia/Tokyo"},
+}, {
+	line:        ":o unixm",
+	expect:      ":o unixmi",
+	expectCands: []string{"unixmicro", "unixmilli"},
+}, {
+	line:        ":ical he",
+	expect:      ":ical hebrew ",
+	expectCands: []string{"hebrew"},
+}, {
+	line:        "tz lond",
+	expect:      "tz Europe/London ",
+	expectCands: []string{"Europe/London"},
+}, {
+	line:        "t",
+	expect:      "t",
+	expectCands: []string{"trunc:", "tz"},
+}, {
+	// Truncation units are completed without
+	// a trailing space after the colon.
+	line:        "now tr",
+	expect:      "now trunc:",
+	expectCands: []string{"trunc:"},
+}, {
+	line:        "now trunc:q",
+	expect:      "now trunc:q",
+	expectCands: []string{"trunc:q", "trunc:quarter"},
+}, {
+	line:        "2024-01-01 @tok",
+	expect:      "2024-01-01 @Asia/Tokyo ",
+	expectCands: []string{"@Asia/Tokyo"},
+}, {
+	line:   ":bogus x",
+	expect: ":bogus x",
+}}
+
+func TestCompleteLine(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range completeLineTests {
+		got, cands := completeLine(test.line)
+		c.Check(got, qt.Equals, test.expect, qt.Commentf("%q", test.line))
+		c.Check(cands, qt.DeepEquals, test.expectCands, qt.Commentf("%q", test.line))
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// runServe runs the serve subcommand, which serves
+// the HTTP API on the -addr address. The arguments
+// hold any flags after the "serve" argument.
+func runServe(args []string) {
+	if err := flag.CommandLine.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	applyConfig()
+	if flag.NArg() > 0 {
+		fatalf("unexpected arguments to serve")
+	}
+	// Check the flags, which provide the defaults for the requests.
+	if _, err := formatter(); err != nil {
+		fatalf("%v", err)
+	}
+	if _, err := timeParser(*inFormat, *tzIn); err != nil {
+		fatalf("%v", err)
+	}
+	log.Printf("listening on %s", *addr)
+	if err := newServer(*addr).ListenAndServe(); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// Limits on the clients of the HTTP API. The requests are
+// all small and quick to answer, so these are generous.
+const (
+	serveReadTimeout  = 10 * time.Second
+	serveWriteTimeout = 30 * time.Second
+	serveIdleTimeout  = 2 * time.Minute
+	maxHeaderBytes    = 64 << 10
+	maxRequestBody    = 64 << 10
+)
+
+// newServer returns a server for the HTTP API
+// listening on the given address.
+func newServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        newServeMux(),
+		ReadTimeout:    serveReadTimeout,
+		WriteTimeout:   serveWriteTimeout,
+		IdleTimeout:    serveIdleTimeout,
+		MaxHeaderBytes: maxHeaderBytes,
+	}
+}
+
+// newServeMux returns the handler for the HTTP API. All the
+// endpoints take their arguments as query parameters and
+// reply with a JSON object; errors are reported as an object
+// with an error field.
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", apiHandler(serveHealth))
+	mux.HandleFunc("/parse", apiHandler(serveParse))
+	mux.HandleFunc("/format", apiHandler(serveFormat))
+	mux.HandleFunc("/delta", apiHandler(serveDelta))
+	mux.HandleFunc("/diff", apiHandler(serveDiff))
+	mux.HandleFunc("/zones", apiHandler(serveZones))
+	return mux
+}
+
+// apiError holds the response to a failed request.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// apiHandler returns an HTTP handler that calls f and writes its
+// result as JSON. Results of type json.RawMessage are written
+// as is. The endpoints don't use request bodies, so large
+// bodies are rejected rather than read.
+func apiHandler(f func(req *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "GET" && req.Method != "HEAD" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(apiError{"method not allowed"})
+			return
+		}
+		if req.ContentLength > maxRequestBody {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(apiError{"request body too large"})
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, maxRequestBody)
+		v, err := f(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			v = apiError{err.Error()}
+		}
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// apiParams holds the parameters common to the endpoints
+// that parse and format times. Empty parameters default
+// to the values of the corresponding flags.
+type apiParams struct {
+	inFormat  string
+	inZone    string
+	outFormat string
+	outZone   string
+}
+
+func newAPIParams(req *http.Request) apiParams {
+	q := req.URL.Query()
+	p := apiParams{
+		inFormat:  q.Get("i"),
+		inZone:    q.Get("itz"),
+		outFormat: q.Get("o"),
+		outZone:   q.Get("otz"),
+	}
+	if p.inFormat == "" {
+		p.inFormat = *inFormat
+	}
+	if p.inZone == "" {
+		p.inZone = *tzIn
+	}
+	if p.outFormat == "" {
+		p.outFormat = *outFormat
+	}
+	if p.outZone == "" {
+		p.outZone = *tzOut
+	}
+	return p
+}
+
+// eval evaluates the time in the query parameter name
+// followed by the deltas and truncations in the d parameters.
+func (p apiParams) eval(req *http.Request, name string) (result, error) {
+	q := req.URL.Query()
+	s := q.Get(name)
+	if s == "" {
+		return result{}, fmt.Errorf("missing %s parameter", name)
+	}
+	parseTime, err := timeParser(p.inFormat, p.inZone)
+	if err != nil {
+		return result{}, err
+	}
+	args := []string{s}
+	for _, d := range q["d"] {
+		if d == "" || d[0] != '-' && d[0] != '+' && !isTruncation(d) {
+			return result{}, fmt.Errorf("invalid delta %q", d)
+		}
+		args = append(args, d)
+	}
+	results, err := evalArgs(args, parseTime)
+	if err != nil {
+		return result{}, err
+	}
+	return results[0], nil
+}
+
+func serveHealth(req *http.Request) (interface{}, error) {
+	return map[string]string{"status": "ok"}, nil
+}
+
+// serveParse describes the time in the t parameter
+// as printed by the json format.
+func serveParse(req *http.Request) (interface{}, error) {
+	p := newAPIParams(req)
+	r, err := p.eval(req, "t")
+	if err != nil {
+		return nil, err
+	}
+	formatTime, err := formatterFor("json", p.outZone)
+	if err != nil {
+		return nil, err
+	}
+	s, err := formatTime(r.t, r.format)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(s), nil
+}
+
+// formatResponse holds the response to the format and delta endpoints.
+type formatResponse struct {
+	// Time holds the formatted time.
+	Time string `json:"time"`
+	// Format holds the format the time was parsed with.
+	Format string `json:"format,omitempty"`
+}
+
+// serveFormat prints the time in the t parameter in
+// the o format.
+func serveFormat(req *http.Request) (interface{}, error) {
+	p := newAPIParams(req)
+	r, err := p.eval(req, "t")
+	if err != nil {
+		return nil, err
+	}
+	formatTime, err := formatterFor(p.outFormat, p.outZone)
+	if err != nil {
+		return nil, err
+	}
+	s, err := formatTime(r.t, r.format)
+	if err != nil {
+		return nil, err
+	}
+	return formatResponse{
+		Time:   s,
+		Format: formatName(r.format),
+	}, nil
+}
+
+// serveDelta is like serveFormat except that
+// at least one delta or truncation is required.
+func serveDelta(req *http.Request) (interface{}, error) {
+	if len(req.URL.Query()["d"]) == 0 {
+		return nil, fmt.Errorf("missing d parameter")
+	}
+	return serveFormat(req)
+}
+
+// diffResponse holds the response to the diff endpoint.
+type diffResponse struct {
+	// Delta holds the difference in calendar units,
+	// as accepted by godate's delta arguments.
+	Delta string `json:"delta"`
+	// Duration holds the difference as a Go duration.
+	Duration string `json:"duration"`
+	// Seconds holds the difference in seconds.
+	Seconds float64 `json:"seconds"`
+}
+
+// serveDiff returns the difference from the time in the
+// from parameter to the time in the to parameter.
+func serveDiff(req *http.Request) (interface{}, error) {
+	p := newAPIParams(req)
+	from, err := p.eval(req, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := p.eval(req, "to")
+	if err != nil {
+		return nil, err
+	}
+	d := to.t.Sub(from.t)
+	return diffResponse{
+		Delta:    timeparse.Diff(from.t, to.t).String(),
+		Duration: d.String(),
+		Seconds:  d.Seconds(),
+	}, nil
+}
+
+// zonesResponse holds the response to the zones endpoint.
+type zonesResponse struct {
+	// Zone holds the time zone that the name parameter
+	// selects, if it selects one.
+	Zone *zoneInfo `json:"zone,omitempty"`
+	// Matches holds all the time zones matching the name.
+	Matches []zoneMatch `json:"matches"`
+}
+
+type zoneInfo struct {
+	Name   string `json:"name"`
+	Abbrev string `json:"abbrev"`
+	Offset int    `json:"offset"`
+}
+
+type zoneMatch struct {
+	Name string `json:"name"`
+	// Link holds the zone that this zone is an alias for, if any.
+	Link string `json:"link,omitempty"`
+}
+
+// serveZones returns the time zones that match the name parameter,
+// as printed by "godate tz", and the zone that godate would use
+// for that name.
+func serveZones(req *http.Request) (interface{}, error) {
+	name := req.URL.Query().Get("name")
+	resp := zonesResponse{
+		Matches: []zoneMatch{},
+	}
+	names := timeparse.MatchZones(name)
+	sort.Strings(names)
+	for _, zone := range names {
+		resp.Matches = append(resp.Matches, zoneMatch{
+			Name: zone,
+			Link: timeparse.ZoneLink(zone),
+		})
+	}
+	if name != "" {
+		if loc, err := loadLocation(name); err == nil && loc != nil {
+			abbrev, offset := time.Now().In(loc).Zone()
+			resp.Zone = &zoneInfo{
+				Name:   loc.String(),
+				Abbrev: abbrev,
+				Offset: offset,
+			}
+		}
+	}
+	return resp, nil
+}
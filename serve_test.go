@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var serveTests = []struct {
+	testName     string
+	method       string
+	url          string
+	body         string
+	expectStatus int
+	expect       map[string]interface{}
+	// expectError, if set, holds a regular expression matching
+	// the error field of the response.
+	expectError string
+}{{
+	testName:     "health",
+	url:          "/health",
+	expectStatus: http.StatusOK,
+	expect:       map[string]interface{}{"status": "ok"},
+}, {
+	testName:     "format",
+	url:          "/format?t=1700000000&i=unix&o=rfc3339&otz=UTC",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"time":   "2023-11-14T22:13:20Z",
+		"format": "unix",
+	},
+}, {
+	testName:     "format-any",
+	url:          "/format?t=2024-01-02T03:04:05Z&o=unixmilli",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"time":   "1704164645000",
+		"format": "rfc3339",
+	},
+}, {
+	testName:     "format-with-delta",
+	url:          "/format?t=2024-01-02T03:04:05Z&d=trunc:d&o=rfc3339&otz=UTC",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"time":   "2024-01-02T00:00:00Z",
+		"format": "rfc3339",
+	},
+}, {
+	testName:     "format-missing-time",
+	url:          "/format",
+	expectStatus: http.StatusBadRequest,
+	expectError:  "missing t parameter",
+}, {
+	testName:     "format-bad-time",
+	url:          "/format?t=yesterday&i=unix",
+	expectStatus: http.StatusBadRequest,
+	expectError:  `.*"yesterday".*`,
+}, {
+	testName:     "format-bad-zone",
+	url:          "/format?t=1700000000&i=unix&otz=Nowhere/Special",
+	expectStatus: http.StatusBadRequest,
+	expectError:  `.*Nowhere/Special.*`,
+}, {
+	testName:     "format-json-input",
+	url:          "/format?t=1&i=json",
+	expectStatus: http.StatusBadRequest,
+	expectError:  "the json format can only be used for output",
+}, {
+	testName:     "delta",
+	url:          "/delta?t=2024-01-31T00:00:00Z&itz=UTC&d=%2B1mo&d=-1d&o=rfc3339&otz=UTC",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"time":   "2024-03-01T00:00:00Z",
+		"format": "rfc3339",
+	},
+}, {
+	testName:     "delta-missing-delta",
+	url:          "/delta?t=now",
+	expectStatus: http.StatusBadRequest,
+	expectError:  "missing d parameter",
+}, {
+	testName:     "delta-unsigned",
+	url:          "/delta?t=now&d=1d",
+	expectStatus: http.StatusBadRequest,
+	expectError:  `invalid delta "1d"`,
+}, {
+	testName:     "delta-bad-unit",
+	url:          "/delta?t=now&d=%2B1fortnight",
+	expectStatus: http.StatusBadRequest,
+	expectError:  `.*unknown unit "fortnight".*`,
+}, {
+	testName:     "diff",
+	url:          "/diff?from=2024-01-01T00:00:00Z&to=2024-03-02T12:00:00Z",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"delta":    "+2mo1d12h0m0s",
+		"duration": "1476h0m0s",
+		"seconds":  5313600.0,
+	},
+}, {
+	testName:     "diff-negative",
+	url:          "/diff?from=2024-01-01T12:00:00Z&to=2024-01-01T00:00:00Z",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"delta":    "-12h0m0s",
+		"duration": "-12h0m0s",
+		"seconds":  -43200.0,
+	},
+}, {
+	testName:     "diff-missing-to",
+	url:          "/diff?from=now",
+	expectStatus: http.StatusBadRequest,
+	expectError:  "missing to parameter",
+}, {
+	testName:     "zones-none",
+	url:          "/zones?name=NoSuchPlaceAnywhere",
+	expectStatus: http.StatusOK,
+	expect: map[string]interface{}{
+		"matches": []interface{}{},
+	},
+}, {
+	testName:     "method-not-allowed",
+	method:       "POST",
+	url:          "/format?t=now",
+	expectStatus: http.StatusMethodNotAllowed,
+	expectError:  "method not allowed",
+}, {
+	testName:     "body-too-large",
+	url:          "/health",
+	body:         strings.Repeat("x", maxRequestBody+1),
+	expectStatus: http.StatusRequestEntityTooLarge,
+	expectError:  "request body too large",
+}}
+
+func TestServe(t *testing.T) {
+	c := qt.New(t)
+	mux := newServeMux()
+	for _, test := range serveTests {
+		c.Run(test.testName, func(c *qt.C) {
+			status, resp := serveRequest(c, mux, test.method, test.url, test.body)
+			c.Assert(status, qt.Equals, test.expectStatus)
+			if test.expectError != "" {
+				c.Assert(resp, qt.HasLen, 1)
+				c.Assert(resp["error"], qt.Matches, test.expectError)
+				return
+			}
+			c.Assert(resp, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestServeParse(t *testing.T) {
+	c := qt.New(t)
+	status, resp := serveRequest(c, newServeMux(), "GET", "/parse?t=1700000000&i=unix&otz=UTC", "")
+	c.Assert(status, qt.Equals, http.StatusOK)
+	c.Check(resp["rfc3339utc"], qt.Equals, "2023-11-14T22:13:20Z")
+	c.Check(resp["unix"], qt.Equals, 1700000000.0)
+	c.Check(resp["zone"], qt.Equals, "UTC")
+	c.Check(resp["format"], qt.Equals, "unix")
+
+	status, resp = serveRequest(c, newServeMux(), "GET", "/parse?i=unix", "")
+	c.Assert(status, qt.Equals, http.StatusBadRequest)
+	c.Assert(resp, qt.DeepEquals, map[string]interface{}{"error": "missing t parameter"})
+}
+
+func TestServeZones(t *testing.T) {
+	c := qt.New(t)
+	status, resp := serveRequest(c, newServeMux(), "GET", "/zones?name=Asia/Tokyo", "")
+	c.Assert(status, qt.Equals, http.StatusOK)
+	c.Assert(resp["zone"], qt.DeepEquals, map[string]interface{}{
+		"name":   "Asia/Tokyo",
+		"abbrev": "JST",
+		"offset": 32400.0,
+	})
+	c.Assert(resp["matches"], qt.Not(qt.HasLen), 0)
+}
+
+func TestNewServer(t *testing.T) {
+	c := qt.New(t)
+	srv := newServer("localhost:0")
+	c.Assert(srv.Addr, qt.Equals, "localhost:0")
+	c.Assert(srv.ReadTimeout, qt.Equals, serveReadTimeout)
+	c.Assert(srv.WriteTimeout, qt.Equals, serveWriteTimeout)
+	c.Assert(srv.IdleTimeout, qt.Equals, serveIdleTimeout)
+	c.Assert(srv.MaxHeaderBytes, qt.Equals, maxHeaderBytes)
+}
+
+// serveRequest sends a request to h and returns the response
+// status and its body decoded as a JSON object.
+func serveRequest(c *qt.C, h http.Handler, method, url, body string) (int, map[string]interface{}) {
+	if method == "" {
+		method = "GET"
+	}
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "application/json")
+	var resp map[string]interface{}
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &resp), qt.IsNil, qt.Commentf("body %q", rec.Body.String()))
+	return rec.Code, resp
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// maxBuckets holds the maximum number of buckets
+// that a histogram may have.
+const maxBuckets = 10000
+
+// stats accumulates the times reported by -stats.
+type stats struct {
+	times []time.Time
+}
+
+func (st *stats) add(t time.Time) {
+	st.times = append(st.times, t)
+}
+
+// gap holds the interval between two successive times.
+type gap struct {
+	from, to time.Time
+}
+
+func (g gap) duration() time.Duration {
+	return g.to.Sub(g.from)
+}
+
+// write writes a summary of the times to w, with times printed
+// by formatTime in the location loc (nil means the location
+// of the times themselves). It reports the ngaps largest
+// intervals between successive times and, if hist is non-empty,
+// a histogram with buckets of that size.
+func (st *stats) write(w io.Writer, formatTime formatFunc, loc *time.Location, ngaps int, hist string) error {
+	times := st.times
+	sort.SliceStable(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	fmt.Fprintf(w, "count\t%d\n", len(times))
+	if len(times) == 0 {
+		return nil
+	}
+	first, last := times[0], times[len(times)-1]
+	for _, f := range []struct {
+		name string
+		t    time.Time
+	}{{"earliest", first}, {"latest", last}} {
+		s, err := formatTime(f.t, "")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\n", f.name, s)
+	}
+	fmt.Fprintf(w, "span\t%v\n", last.Sub(first))
+	if len(times) > 1 {
+		gaps := make([]gap, len(times)-1)
+		for i := range gaps {
+			gaps[i] = gap{times[i], times[i+1]}
+		}
+		// Sort the largest gaps first, leaving gaps of
+		// the same size in time order.
+		sort.SliceStable(gaps, func(i, j int) bool {
+			return gaps[i].duration() > gaps[j].duration()
+		})
+		fmt.Fprintf(w, "median interval\t%v\n", medianInterval(gaps))
+		if ngaps > len(gaps) {
+			ngaps = len(gaps)
+		}
+		if ngaps > 0 {
+			fmt.Fprintf(w, "largest gaps\n")
+		}
+		for _, g := range gaps[:ngaps] {
+			from, err := formatTime(g.from, "")
+			if err != nil {
+				return err
+			}
+			to, err := formatTime(g.to, "")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t%v\t%s\t%s\n", g.duration(), from, to)
+		}
+	}
+	if hist == "" {
+		return nil
+	}
+	if loc == nil {
+		loc = first.Location()
+	}
+	labels, counts, err := histogram(times, loc, hist, formatTime)
+	if err != nil {
+		return err
+	}
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	fmt.Fprintf(w, "histogram\n")
+	for i, label := range labels {
+		bar := 0
+		if max > 0 {
+			bar = (counts[i]*50 + max - 1) / max
+		}
+		fmt.Fprintf(w, "\t%s\t%d\t%s\n", label, counts[i], strings.Repeat("#", bar))
+	}
+	return nil
+}
+
+// medianInterval returns the median of the given gaps,
+// which must be sorted by duration, largest first.
+func medianInterval(gaps []gap) time.Duration {
+	n := len(gaps)
+	if n%2 == 1 {
+		return gaps[n/2].duration()
+	}
+	a, b := gaps[n/2].duration(), gaps[n/2-1].duration()
+	return a + (b-a)/2
+}
+
+// histogram returns the bucket labels and counts for the sorted times
+// bucketed by the given size in the location loc. The size is
+// either "weekday" or a positive delta such as 1h or 1d.
+func histogram(times []time.Time, loc *time.Location, size string, formatTime formatFunc) ([]string, []int, error) {
+	if size == "weekday" {
+		labels := make([]string, 7)
+		counts := make([]int, 7)
+		for i := range labels {
+			// Start the week on Monday.
+			labels[i] = time.Weekday((i + 1) % 7).String()
+		}
+		for _, t := range times {
+			counts[(t.In(loc).Weekday()+6)%7]++
+		}
+		return labels, counts, nil
+	}
+	d, err := timeparse.ParseDelta(size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bad histogram bucket size: %v", err)
+	}
+	if d.Years < 0 || d.Months < 0 || d.Days < 0 || d.Duration < 0 || d == (timeparse.Delta{}) {
+		return nil, nil, fmt.Errorf("histogram bucket size %q must be positive", size)
+	}
+	// Buckets are aligned to the start of the year, month or
+	// day containing the first time, depending on the bucket size.
+	t0 := times[0].In(loc)
+	var start time.Time
+	switch {
+	case d.Years != 0:
+		start = time.Date(t0.Year(), 1, 1, 0, 0, 0, 0, loc)
+	case d.Months != 0:
+		start = time.Date(t0.Year(), t0.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		start = time.Date(t0.Year(), t0.Month(), t0.Day(), 0, 0, 0, 0, loc)
+	}
+	var labels []string
+	var counts []int
+	i := 0
+	for i < len(times) {
+		end := d.Add(start)
+		if !end.After(start) {
+			return nil, nil, fmt.Errorf("histogram bucket size %q is too small", size)
+		}
+		n := 0
+		for ; i < len(times) && times[i].Before(end); i++ {
+			n++
+		}
+		// Skip the leading buckets before the first time.
+		if n > 0 || len(counts) > 0 {
+			if len(counts) >= maxBuckets {
+				return nil, nil, fmt.Errorf("histogram has more than %d buckets", maxBuckets)
+			}
+			label, err := formatTime(start, "")
+			if err != nil {
+				return nil, nil, err
+			}
+			labels = append(labels, label)
+			counts = append(counts, n)
+		}
+		start = end
+	}
+	return labels, counts, nil
+}
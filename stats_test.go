@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// statsTimes holds the times used by the stats tests, out of order.
+var statsTimes = []time.Time{
+	time.Date(2024, 3, 5, 9, 10, 0, 0, time.UTC),
+	time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC),
+	time.Date(2024, 3, 4, 10, 5, 0, 0, time.UTC),
+	time.Date(2024, 3, 4, 10, 15, 0, 0, time.UTC),
+	time.Date(2024, 3, 4, 10, 20, 0, 0, time.UTC),
+	time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC),
+}
+
+var statsTests = []struct {
+	testName    string
+	times       []time.Time
+	gaps        int
+	hist        string
+	expect      string
+	expectError string
+}{{
+	testName: "empty",
+	gaps:     5,
+	expect:   "count\t0\n",
+}, {
+	testName: "one",
+	times:    statsTimes[:1],
+	gaps:     5,
+	expect: `
+count	1
+earliest	2024-03-05T09:10:00Z
+latest	2024-03-05T09:10:00Z
+span	0s
+`[1:],
+}, {
+	// The two 10 minute gaps are equal, so
+	// they're listed in time order.
+	testName: "gaps",
+	times:    statsTimes,
+	gaps:     3,
+	expect: `
+count	7
+earliest	2024-03-04T10:00:00Z
+latest	2024-03-10T12:00:00Z
+span	146h0m0s
+median interval	10m0s
+largest gaps
+	122h50m0s	2024-03-05T09:10:00Z	2024-03-10T12:00:00Z
+	22h40m0s	2024-03-04T10:20:00Z	2024-03-05T09:00:00Z
+	10m0s	2024-03-04T10:05:00Z	2024-03-04T10:15:00Z
+`[1:],
+}, {
+	testName: "all-gaps",
+	times:    statsTimes[1:5],
+	gaps:     10,
+	expect: `
+count	4
+earliest	2024-03-04T10:00:00Z
+latest	2024-03-04T10:20:00Z
+span	20m0s
+median interval	5m0s
+largest gaps
+	10m0s	2024-03-04T10:05:00Z	2024-03-04T10:15:00Z
+	5m0s	2024-03-04T10:00:00Z	2024-03-04T10:05:00Z
+	5m0s	2024-03-04T10:15:00Z	2024-03-04T10:20:00Z
+`[1:],
+}, {
+	testName: "weekday-histogram",
+	times:    statsTimes,
+	hist:     "weekday",
+	expect: `
+count	7
+earliest	2024-03-04T10:00:00Z
+latest	2024-03-10T12:00:00Z
+span	146h0m0s
+median interval	10m0s
+histogram
+	Monday	4	##################################################
+	Tuesday	2	#########################
+	Wednesday	0	
+	Thursday	0	
+	Friday	0	
+	Saturday	0	
+	Sunday	1	#############
+`[1:],
+}, {
+	testName: "daily-histogram",
+	times:    statsTimes[:5],
+	hist:     "1d",
+	expect: `
+count	5
+earliest	2024-03-04T10:00:00Z
+latest	2024-03-05T09:10:00Z
+span	23h10m0s
+median interval	7m30s
+histogram
+	2024-03-04T00:00:00Z	4	##################################################
+	2024-03-05T00:00:00Z	1	#############
+`[1:],
+}, {
+	testName:    "negative-bucket",
+	times:       statsTimes,
+	hist:        "-1h",
+	expectError: `histogram bucket size "-1h" must be positive`,
+}, {
+	testName:    "bad-bucket",
+	times:       statsTimes,
+	hist:        "fortnight",
+	expectError: `bad histogram bucket size: .*`,
+}, {
+	testName:    "too-many-buckets",
+	times:       statsTimes,
+	hist:        "1s",
+	expectError: `histogram has more than 10000 buckets`,
+}}
+
+func TestStats(t *testing.T) {
+	c := qt.New(t)
+	formatTime, err := formatterFor("rfc3339", "")
+	c.Assert(err, qt.IsNil)
+	for _, test := range statsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			st := &stats{}
+			for _, t := range test.times {
+				st.add(t)
+			}
+			var buf bytes.Buffer
+			err := st.write(&buf, formatTime, time.UTC, test.gaps, test.hist)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// convertFile reads times from r according to the flags
+// and writes them to w, including only the records
+// with times in the given window. With -stats, it
+// writes a summary of the times instead.
+func convertFile(r io.Reader, w io.Writer, parseTime parseFunc, formatTime formatFunc, win *window) error {
+	modes := 0
+	for _, set := range []bool{*replace, len(columns) > 0, len(jsonPaths) > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("only one of -replace, -col and -json may be used")
+	}
+	if *showStats {
+		loc, err := loadLocation(*tzOut)
+		if err != nil {
+			return err
+		}
+		st := &stats{}
+		win.seen = st.add
+		if err := convertRecords(r, ioutil.Discard, parseTime, formatTime, win); err != nil {
+			return err
+		}
+		return st.write(w, formatTime, loc, *gaps, *hist)
+	}
+	return convertRecords(r, w, parseTime, formatTime, win)
+}
+
+// convertRecords converts the times in r in the mode selected
+// by the flags, writing the result to w.
+func convertRecords(r io.Reader, w io.Writer, parseTime parseFunc, formatTime formatFunc, win *window) error {
+	if len(jsonPaths) > 0 {
+		return convertJSON(r, w, jsonPaths, formatTime, win)
+	}
+	if len(columns) > 0 {
+		return convertColumns(r, w, columns, formatTime, win)
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	if *replace {
+		pat, err := timePattern(*inFormat)
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line, t, ok := replaceTimes(scanner.Text(), pat, parseTime, formatTime)
+			if !win.include(t, ok) {
+				continue
+			}
+			if *orig {
+				line = scanner.Text()
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		t, tf, err := parseTime(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse error on %q: %v\n", scanner.Text(), err)
+			continue
+		}
+		if !win.include(t, true) {
+			continue
+		}
+		if *orig {
+			fmt.Fprintf(bw, "%s\n", scanner.Text())
+			continue
+		}
+		s, err := formatTime(t, tf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot format %q: %v\n", scanner.Text(), err)
+			continue
+		}
+		fmt.Fprintf(bw, "%s\n", s)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
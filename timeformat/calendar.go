@@ -0,0 +1,298 @@
+package timeformat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Calendar converts dates between the Gregorian calendar
+// and another calendar system. Only the Year, Month, Day
+// and Era fields of a Date are converted; the other fields
+// are left unchanged.
+type Calendar interface {
+	// Name returns the name of the calendar, for example "hebrew".
+	Name() string
+
+	// FromGregorian returns the date in the calendar that
+	// corresponds to the Gregorian date d.
+	FromGregorian(d *Date) (*Date, error)
+
+	// ToGregorian returns the Gregorian date that corresponds
+	// to d, a date in the calendar.
+	ToGregorian(d *Date) (*Date, error)
+
+	// MonthNames returns the names of the months in the
+	// given year, starting with month 1, or nil if the
+	// calendar does not name its months.
+	MonthNames(year int) []string
+
+	// Eras returns the names of the calendar's eras,
+	// or nil if it does not have named eras.
+	Eras() []string
+}
+
+// calendars holds all the known calendars, keyed by name.
+var calendars = map[string]Calendar{
+	"gregorian": gregorianCalendar{},
+	"isoweek":   isoWeekCalendar{},
+	"hebrew":    hebrewCalendar{},
+	"islamic":   islamicCalendar{},
+	"umalqura":  ummAlQuraCalendar{},
+	"persian":   persianCalendar{},
+	"buddhist":  offsetCalendar{"buddhist", 543},
+	"roc":       offsetCalendar{"roc", -1911},
+	"japanese":  japaneseCalendar{},
+}
+
+// LookupCalendar returns the calendar with the given name
+// (case-insensitive).
+func LookupCalendar(name string) (Calendar, error) {
+	cal, ok := calendars[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown calendar %q", name)
+	}
+	return cal, nil
+}
+
+// CalendarNames returns the names of all the known calendars
+// in alphabetical order.
+func CalendarNames() []string {
+	names := make([]string, 0, len(calendars))
+	for name := range calendars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Days are numbered from 1 for the 1st of January in year 1 of
+// the proleptic Gregorian calendar ("fixed" dates, as used in
+// Dershowitz and Reingold's Calendrical Calculations). The day
+// numbers make conversions between calendars straightforward.
+
+// unixEpochDay holds the day number of 1970-01-01.
+const unixEpochDay = 719163
+
+// dayFromGregorian returns the day number of the given Gregorian date.
+func dayFromGregorian(year int, month time.Month, day int) int {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return floorDiv(int(t.Unix()), 86400) + unixEpochDay
+}
+
+// gregorianFromDay returns the Gregorian date with the given day number.
+func gregorianFromDay(n int) (year int, month time.Month, day int) {
+	return time.Unix(int64(n-unixEpochDay)*86400, 0).UTC().Date()
+}
+
+// withDate returns a copy of d with the given date.
+func withDate(d *Date, era string, year int, month time.Month, day int) *Date {
+	d1 := *d
+	d1.Era, d1.Year, d1.Month, d1.Day = era, year, month, day
+	return &d1
+}
+
+// checkDate returns an error if month or day is outside
+// the range 1..nmonths or 1..ndays respectively.
+func checkDate(cal Calendar, d *Date, nmonths int, ndays func() int) error {
+	if d.Month < 1 || int(d.Month) > nmonths {
+		return fmt.Errorf("month %d out of range in %s calendar", d.Month, cal.Name())
+	}
+	if n := ndays(); d.Day < 1 || d.Day > n {
+		return fmt.Errorf("day %d out of range in %s calendar", d.Day, cal.Name())
+	}
+	return nil
+}
+
+// floorDiv returns a/b rounded towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMod returns a modulo b with the sign of b.
+func floorMod(a, b int) int {
+	return a - b*floorDiv(a, b)
+}
+
+// gregorianCalendar implements Calendar for the Gregorian calendar
+// used by the time package.
+type gregorianCalendar struct{}
+
+func (gregorianCalendar) Name() string { return "gregorian" }
+
+func (gregorianCalendar) FromGregorian(d *Date) (*Date, error) {
+	return withDate(d, "", d.Year, d.Month, d.Day), nil
+}
+
+func (cal gregorianCalendar) ToGregorian(d *Date) (*Date, error) {
+	err := checkDate(cal, d, 12, func() int {
+		return daysIn(d.Month, d.Year)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withDate(d, "", d.Year, d.Month, d.Day), nil
+}
+
+func (gregorianCalendar) MonthNames(year int) []string {
+	return English.Months[:]
+}
+
+func (gregorianCalendar) Eras() []string {
+	return nil
+}
+
+// daysIn returns the number of days in the given Gregorian month.
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isoWeekCalendar implements Calendar for ISO 8601 week dates.
+// The year is the ISO week-numbering year, the month
+// is the week number and the day is the day of the
+// week, from 1 (Monday) to 7 (Sunday).
+type isoWeekCalendar struct{}
+
+func (isoWeekCalendar) Name() string { return "isoweek" }
+
+func (isoWeekCalendar) FromGregorian(d *Date) (*Date, error) {
+	t := time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+	year, week := t.ISOWeek()
+	return withDate(d, "", year, time.Month(week), isoWeekday(t.Weekday())), nil
+}
+
+func (cal isoWeekCalendar) ToGregorian(d *Date) (*Date, error) {
+	err := checkDate(cal, d, isoWeeksIn(d.Year), func() int {
+		return 7
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The 4th of January is always in week 1.
+	jan4 := dayFromGregorian(d.Year, time.January, 4)
+	week1 := jan4 - isoWeekday(time.Date(d.Year, time.January, 4, 0, 0, 0, 0, time.UTC).Weekday()) + 1
+	year, month, day := gregorianFromDay(week1 + (int(d.Month)-1)*7 + d.Day - 1)
+	return withDate(d, "", year, month, day), nil
+}
+
+func (isoWeekCalendar) MonthNames(year int) []string {
+	return nil
+}
+
+func (isoWeekCalendar) Eras() []string {
+	return nil
+}
+
+// isoWeekday returns the ISO day number of the given weekday.
+func isoWeekday(wd time.Weekday) int {
+	if wd == time.Sunday {
+		return 7
+	}
+	return int(wd)
+}
+
+// isoWeeksIn returns the number of ISO weeks in the given year.
+func isoWeeksIn(year int) int {
+	// The 28th of December is always in the last week.
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+// offsetCalendar implements Calendar for calendars that differ
+// from the Gregorian calendar only in their year numbering.
+type offsetCalendar struct {
+	name string
+	// offset holds the calendar year of Gregorian year 0.
+	offset int
+}
+
+func (cal offsetCalendar) Name() string { return cal.name }
+
+func (cal offsetCalendar) FromGregorian(d *Date) (*Date, error) {
+	if d.Year+cal.offset < 1 {
+		return nil, fmt.Errorf("date is before the start of the %s calendar", cal.name)
+	}
+	return withDate(d, "", d.Year+cal.offset, d.Month, d.Day), nil
+}
+
+func (cal offsetCalendar) ToGregorian(d *Date) (*Date, error) {
+	return gregorianCalendar{}.ToGregorian(withDate(d, "", d.Year-cal.offset, d.Month, d.Day))
+}
+
+func (offsetCalendar) MonthNames(year int) []string {
+	return English.Months[:]
+}
+
+func (offsetCalendar) Eras() []string {
+	return nil
+}
+
+// japaneseEras holds the Japanese imperial eras since the
+// calendar was reformed in 1873. The Meiji era is extended
+// back to its official start so that its year numbering
+// is consistent.
+var japaneseEras = []struct {
+	name  string
+	start time.Time
+}{
+	{"Meiji", time.Date(1868, time.October, 23, 0, 0, 0, 0, time.UTC)},
+	{"Taisho", time.Date(1912, time.July, 30, 0, 0, 0, 0, time.UTC)},
+	{"Showa", time.Date(1926, time.December, 25, 0, 0, 0, 0, time.UTC)},
+	{"Heisei", time.Date(1989, time.January, 8, 0, 0, 0, 0, time.UTC)},
+	{"Reiwa", time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+// japaneseCalendar implements Calendar for the Japanese imperial
+// calendar, which counts years from the start of each era.
+type japaneseCalendar struct{}
+
+func (japaneseCalendar) Name() string { return "japanese" }
+
+func (japaneseCalendar) FromGregorian(d *Date) (*Date, error) {
+	t := time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+	for i := len(japaneseEras) - 1; i >= 0; i-- {
+		era := japaneseEras[i]
+		if !t.Before(era.start) {
+			return withDate(d, era.name, d.Year-era.start.Year()+1, d.Month, d.Day), nil
+		}
+	}
+	return nil, fmt.Errorf("date is before the start of the Meiji era")
+}
+
+func (cal japaneseCalendar) ToGregorian(d *Date) (*Date, error) {
+	for i, era := range japaneseEras {
+		if !strings.EqualFold(era.name, d.Era) && (len(d.Era) != 1 || !strings.EqualFold(era.name[:1], d.Era)) {
+			continue
+		}
+		gd, err := gregorianCalendar{}.ToGregorian(withDate(d, "", d.Year+era.start.Year()-1, d.Month, d.Day))
+		if err != nil {
+			return nil, err
+		}
+		t := time.Date(gd.Year, gd.Month, gd.Day, 0, 0, 0, 0, time.UTC)
+		if d.Year < 1 || t.Before(era.start) || i+1 < len(japaneseEras) && !t.Before(japaneseEras[i+1].start) {
+			return nil, fmt.Errorf("date is outside the %s era", era.name)
+		}
+		return gd, nil
+	}
+	if d.Era == "" {
+		return nil, fmt.Errorf("no era in Japanese date")
+	}
+	return nil, fmt.Errorf("unknown Japanese era %q", d.Era)
+}
+
+func (japaneseCalendar) MonthNames(year int) []string {
+	return English.Months[:]
+}
+
+func (japaneseCalendar) Eras() []string {
+	names := make([]string, len(japaneseEras))
+	for i, era := range japaneseEras {
+		names[i] = era.name
+	}
+	return names
+}
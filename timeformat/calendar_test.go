@@ -0,0 +1,228 @@
+package timeformat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var calendarTests = []struct {
+	calendar  string
+	gregorian string
+	expect    string
+}{{
+	calendar:  "isoweek",
+	gregorian: "2024-12-30",
+	expect:    "2025-01-01",
+}, {
+	calendar:  "isoweek",
+	gregorian: "2021-01-03",
+	expect:    "2020-53-07",
+}, {
+	calendar:  "hebrew",
+	gregorian: "2024-10-03",
+	expect:    "5785-01-01",
+}, {
+	// 1 Adar II 5784.
+	calendar:  "hebrew",
+	gregorian: "2024-03-11",
+	expect:    "5784-07-01",
+}, {
+	// 15 Nisan 5784.
+	calendar:  "hebrew",
+	gregorian: "2024-04-23",
+	expect:    "5784-08-15",
+}, {
+	// 29 Kislev 5785.
+	calendar:  "hebrew",
+	gregorian: "2024-12-30",
+	expect:    "5785-03-29",
+}, {
+	calendar:  "islamic",
+	gregorian: "1990-01-01",
+	expect:    "1410-06-03",
+}, {
+	calendar:  "islamic",
+	gregorian: "1992-07-01",
+	expect:    "1412-12-30",
+}, {
+	calendar:  "umalqura",
+	gregorian: "1990-01-01",
+	expect:    "1410-06-04",
+}, {
+	calendar:  "umalqura",
+	gregorian: "2024-03-11",
+	expect:    "1445-09-01",
+}, {
+	calendar:  "umalqura",
+	gregorian: "1937-03-14",
+	expect:    "1356-01-01",
+}, {
+	calendar:  "persian",
+	gregorian: "2024-03-20",
+	expect:    "1403-01-01",
+}, {
+	calendar:  "persian",
+	gregorian: "2025-03-20",
+	expect:    "1403-12-30",
+}, {
+	calendar:  "persian",
+	gregorian: "2024-10-03",
+	expect:    "1403-07-12",
+}, {
+	calendar:  "buddhist",
+	gregorian: "2024-10-03",
+	expect:    "2567-10-03",
+}, {
+	calendar:  "roc",
+	gregorian: "2024-10-03",
+	expect:    "113-10-03",
+}, {
+	calendar:  "japanese",
+	gregorian: "2019-05-01",
+	expect:    "Reiwa 1-05-01",
+}, {
+	calendar:  "japanese",
+	gregorian: "2019-04-30",
+	expect:    "Heisei 31-04-30",
+}}
+
+func TestCalendars(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range calendarTests {
+		c.Run(test.calendar+"/"+test.gregorian, func(c *qt.C) {
+			cal, err := LookupCalendar(test.calendar)
+			c.Assert(err, qt.IsNil)
+			g, err := time.Parse("2006-01-02", test.gregorian)
+			c.Assert(err, qt.IsNil)
+			d, err := cal.FromGregorian(TimeDate(g))
+			c.Assert(err, qt.IsNil)
+			got := fmt.Sprintf("%d-%02d-%02d", d.Year, d.Month, d.Day)
+			if d.Era != "" {
+				got = d.Era + " " + got
+			}
+			c.Assert(got, qt.Equals, test.expect)
+			back, err := cal.ToGregorian(d)
+			c.Assert(err, qt.IsNil)
+			c.Assert(back.Time(), qt.Equals, g)
+		})
+	}
+}
+
+func TestCalendarRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	for _, name := range CalendarNames() {
+		cal, err := LookupCalendar(name)
+		c.Assert(err, qt.IsNil)
+		for day := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC); day.Year() < 3000; day = day.AddDate(0, 0, 17) {
+			d, err := cal.FromGregorian(TimeDate(day))
+			if err != nil {
+				// Not all dates can be represented in all calendars.
+				continue
+			}
+			back, err := cal.ToGregorian(d)
+			c.Assert(err, qt.IsNil, qt.Commentf("%s %v", name, day))
+			c.Assert(back.Time(), qt.Equals, day, qt.Commentf("%s %#v", name, d))
+		}
+	}
+}
+
+func TestCalendarOutOfRange(t *testing.T) {
+	c := qt.New(t)
+	cal, err := LookupCalendar("umalqura")
+	c.Assert(err, qt.IsNil)
+	_, err = cal.FromGregorian(TimeDate(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)))
+	c.Assert(err, qt.ErrorMatches, `date is outside the range of the Umm al-Qura calendar`)
+	cal, err = LookupCalendar("hebrew")
+	c.Assert(err, qt.IsNil)
+	// 5784 is a leap year with 13 months; 5785 is not.
+	_, err = cal.ToGregorian(&Date{Year: 5785, Month: 13, Day: 1})
+	c.Assert(err, qt.ErrorMatches, `month 13 out of range in hebrew calendar`)
+	_, err = cal.ToGregorian(&Date{Year: 5784, Month: 13, Day: 1})
+	c.Assert(err, qt.IsNil)
+}
+
+var styleTests = []struct {
+	calendar string
+	locale   string
+	layout   string
+	value    string
+	time     time.Time
+}{{
+	calendar: "hebrew",
+	layout:   "2 January 2006",
+	value:    "1 Adar II 5784",
+	time:     time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+}, {
+	calendar: "islamic",
+	layout:   "2006-01-02 15:04:05",
+	value:    "1412-12-30 10:11:12",
+	time:     time.Date(1992, 7, 1, 10, 11, 12, 0, time.UTC),
+}, {
+	calendar: "persian",
+	layout:   "Monday 2 January 2006",
+	value:    "Wednesday 1 Farvardin 1403",
+	time:     time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+}, {
+	calendar: "isoweek",
+	layout:   "2006-W01-2",
+	value:    "2025-W01-1",
+	time:     time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+}, {
+	calendar: "japanese",
+	layout:   "2006/01/02",
+	value:    "Reiwa 6/10/03",
+	time:     time.Date(2024, 10, 3, 0, 0, 0, 0, time.UTC),
+}, {
+	calendar: "japanese",
+	layout:   "06.01.02",
+	value:    "H31.04.30",
+	time:     time.Date(2019, 4, 30, 0, 0, 0, 0, time.UTC),
+}, {
+	calendar: "roc",
+	locale:   "fr",
+	layout:   "2 Jan 2006 3:04PM",
+	value:    "3 oct. 113 2:30PM",
+	time:     time.Date(2024, 10, 3, 14, 30, 0, 0, time.UTC),
+}, {
+	calendar: "buddhist",
+	layout:   "2006.002",
+	value:    "2567.277",
+	time:     time.Date(2024, 10, 3, 0, 0, 0, 0, time.UTC),
+}}
+
+func TestStyle(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range styleTests {
+		c.Run(test.calendar+"/"+test.value, func(c *qt.C) {
+			cal, err := LookupCalendar(test.calendar)
+			c.Assert(err, qt.IsNil)
+			s := Style{Calendar: cal}
+			if test.locale != "" {
+				s.Locale, err = LookupLocale(test.locale)
+				c.Assert(err, qt.IsNil)
+			}
+			got, err := s.Format(test.time, test.layout)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, test.value)
+			tm, err := s.Parse(test.layout, test.value)
+			c.Assert(err, qt.IsNil)
+			c.Assert(tm, qt.Equals, test.time)
+		})
+	}
+}
+
+func TestStyleParseErrors(t *testing.T) {
+	c := qt.New(t)
+	cal, err := LookupCalendar("hebrew")
+	c.Assert(err, qt.IsNil)
+	s := Style{Calendar: cal}
+	_, err = s.Parse("2 January", "1 Nisan")
+	c.Assert(err, qt.ErrorMatches, `layout "2 January" does not specify a full date in the hebrew calendar`)
+	_, err = s.Parse("2 January 2006", "1 Adar II 5785")
+	c.Assert(err, qt.ErrorMatches, `month "Adar II" not found in year 5785 of hebrew calendar`)
+	_, err = s.Parse("2006-01-02", "5785-02-30x")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "5785-02-30x" as "2006-01-02" in hebrew calendar`)
+}
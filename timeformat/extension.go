@@ -0,0 +1,337 @@
+package timeformat
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// As well as the elements understood by the time package, layouts
+// may contain extension elements that start with a percent sign, and
+// backslash escapes. These are recognized by nextStdChunk and formatted
+// and parsed by Style.
+const (
+	stdEscape      = iota + stdExtension // "\x"
+	stdPercent                           // "%%"
+	stdQuarter                           // "%Q"
+	stdFiscalYear                        // "%F"
+	stdFiscalYear2                       // "%f"
+	stdFiscalWeek                        // "%W"
+	stdISOWeek                           // "%V"
+	stdISOYear                           // "%G"
+	stdISOWeekday                        // "%u"
+	stdNumWeekday                        // "%w"
+	stdYearDay                           // "%j"
+	stdSmartHour                         // "%i"
+	stdOrdinal                           // "%o"
+
+	stdExtension = 4 << 8 // not understood by the time package
+)
+
+// extensionStds maps the letter following a percent
+// sign to its extension element.
+var extensionStds = map[byte]int{
+	'%': stdPercent,
+	'Q': stdQuarter,
+	'F': stdFiscalYear,
+	'f': stdFiscalYear2,
+	'W': stdFiscalWeek,
+	'V': stdISOWeek,
+	'G': stdISOYear,
+	'u': stdISOWeekday,
+	'w': stdNumWeekday,
+	'j': stdYearDay,
+	'i': stdSmartHour,
+	'o': stdOrdinal,
+}
+
+// extensionStd returns the extension element at the start of
+// s, which starts with a percent sign, and its length,
+// or 0 if there is none.
+func extensionStd(s string) (std, n int) {
+	if len(s) < 2 {
+		return 0, 0
+	}
+	if std, ok := extensionStds[s[1]]; ok {
+		return std, 2
+	}
+	return 0, 0
+}
+
+// isExtension reports whether std is an extension element.
+func isExtension(std int) bool {
+	return std&stdExtension != 0
+}
+
+// hasExtensions reports whether the layout contains
+// any extension elements.
+func hasExtensions(layout string) bool {
+	if !strings.ContainsAny(layout, `%\`) {
+		return false
+	}
+	for layout != "" {
+		_, std, suffix := nextStdChunk(layout)
+		if std == 0 {
+			return false
+		}
+		if isExtension(std) {
+			return true
+		}
+		layout = suffix
+	}
+	return false
+}
+
+// formatExtension returns t formatted according to
+// the extension element std, which is held in chunk.
+func (s Style) formatExtension(t time.Time, std int, chunk string) string {
+	start := s.fiscalStart()
+	switch std & stdMask {
+	case stdEscape:
+		return chunk[1:]
+	case stdPercent:
+		return "%"
+	case stdQuarter:
+		return strconv.Itoa(fiscalQuarter(t, start))
+	case stdFiscalYear:
+		return strconv.Itoa(fiscalYear(t, start))
+	case stdFiscalYear2:
+		return fmt.Sprintf("%02d", floorMod(fiscalYear(t, start), 100))
+	case stdFiscalWeek:
+		return fmt.Sprintf("%02d", fiscalWeek(t, start))
+	case stdISOWeek:
+		_, week := t.ISOWeek()
+		return fmt.Sprintf("%02d", week)
+	case stdISOYear:
+		year, _ := t.ISOWeek()
+		return strconv.Itoa(year)
+	case stdISOWeekday:
+		return strconv.Itoa(isoWeekday(t.Weekday()))
+	case stdNumWeekday:
+		return strconv.Itoa(int(t.Weekday()))
+	case stdYearDay:
+		return fmt.Sprintf("%03d", t.YearDay())
+	case stdSmartHour:
+		hour := t.Hour() % 12
+		if hour == 0 {
+			hour = 12
+		}
+		if t.Minute() == 0 {
+			return strconv.Itoa(hour)
+		}
+		return fmt.Sprintf("%d:%02d", hour, t.Minute())
+	case stdOrdinal:
+		return ordinalSuffix(t.Day())
+	}
+	panic("unknown extension element")
+}
+
+// ordinalSuffix returns the English ordinal suffix for n,
+// for example "rd" for 3 and "th" for 13.
+func ordinalSuffix(n int) string {
+	if n%100/10 != 1 {
+		switch n % 10 {
+		case 1:
+			return "st"
+		case 2:
+			return "nd"
+		case 3:
+			return "rd"
+		}
+	}
+	return "th"
+}
+
+// parseExtensions parses a value with a layout that contains extension
+// elements. The other elements are parsed by the time package.
+func (s Style) parseExtensions(layout, value string, loc *time.Location) (time.Time, error) {
+	elems, restLayout, restValue, ok := s.splitLayout(layout, value, isExtension)
+	if !ok {
+		return time.Time{}, fmt.Errorf("cannot parse %q as %q", value, layout)
+	}
+	t, err := s.locale().ParseInLocation(restLayout, restValue, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var v extensionValues
+	for _, e := range elems {
+		n, _ := strconv.Atoi(e.text)
+		switch e.std & stdMask {
+		case stdQuarter:
+			v.fiscal.quarter = n
+		case stdFiscalYear:
+			v.fiscal.year = n
+		case stdFiscalYear2:
+			// Two digit years are interpreted as by the time package.
+			v.fiscal.year = n + 1900
+			if n < 69 {
+				v.fiscal.year += 100
+			}
+		case stdFiscalWeek:
+			v.fiscal.week = n
+		case stdISOWeek:
+			v.isoWeek = n
+		case stdISOYear:
+			v.isoYear = n
+		case stdISOWeekday:
+			v.weekday = n
+		case stdNumWeekday:
+			v.weekday = isoWeekday(time.Weekday(n))
+		case stdYearDay:
+			v.yday = n
+		case stdSmartHour:
+			v.clock = e.text
+		case stdOrdinal:
+			v.ordinal = e.text
+		}
+	}
+	return v.resolve(t, LayoutComponents(restLayout), s.fiscalStart())
+}
+
+// extensionValues holds the values of the extension elements
+// parsed from a time. Fields are zero when the element
+// was not present.
+type extensionValues struct {
+	fiscal  fiscalPeriod
+	isoYear int
+	isoWeek int
+	// weekday holds the ISO day of the week,
+	// from 1 (Monday) to 7 (Sunday).
+	weekday int
+	yday    int
+	// clock holds the text of a %i element,
+	// for example "3" or "3:30".
+	clock   string
+	ordinal string
+}
+
+// resolve returns the time specified by v and t, which holds the
+// rest of the parsed time and has the given components. If t has
+// no month or day, the date is taken from the ISO week, the day of
+// the year or the fiscal period, in that order of preference,
+// using the year of t when there's no ISO or fiscal year. All the
+// values in v are then checked against the resulting date.
+func (v extensionValues) resolve(t time.Time, have Components, start time.Month) (time.Time, error) {
+	year, month, day := t.Date()
+	hour, min := t.Hour(), t.Minute()
+	if v.clock != "" {
+		// The time package leaves the hour as 12 for
+		// PM or 0 otherwise, so add the hour to that.
+		i := strings.IndexByte(v.clock+":", ':')
+		h, _ := strconv.Atoi(v.clock[:i])
+		hour += h % 12
+		min = 0
+		if i < len(v.clock) {
+			min, _ = strconv.Atoi(v.clock[i+1:])
+		}
+	}
+	if have&(Month|Day) == 0 {
+		switch {
+		case v.isoWeek != 0 || v.isoYear != 0:
+			d := &Date{Year: year, Month: time.Month(v.isoWeek), Day: v.weekday}
+			if v.isoYear != 0 {
+				d.Year = v.isoYear
+			}
+			if d.Month == 0 {
+				d.Month = 1
+			}
+			if d.Day == 0 {
+				d.Day = 1
+			}
+			gd, err := isoWeekCalendar{}.ToGregorian(d)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("ISO week %d out of range in %d", d.Month, d.Year)
+			}
+			year, month, day = gd.Year, gd.Month, gd.Day
+		case v.yday != 0:
+			month, day = time.January, v.yday
+		case v.fiscal != (fiscalPeriod{}):
+			year, month, day = v.fiscal.date(year, start)
+		}
+	}
+	t = time.Date(year, month, day, hour, min, t.Second(), t.Nanosecond(), t.Location())
+	isoYear, isoWeek := t.ISOWeek()
+	switch {
+	case v.isoYear != 0 && isoYear != v.isoYear:
+		return time.Time{}, fmt.Errorf("date is not in ISO week-numbering year %d", v.isoYear)
+	case v.isoWeek != 0 && isoWeek != v.isoWeek:
+		return time.Time{}, fmt.Errorf("date is not in ISO week %d", v.isoWeek)
+	case v.weekday != 0 && isoWeekday(t.Weekday()) != v.weekday:
+		return time.Time{}, fmt.Errorf("date is not on weekday %d", v.weekday)
+	case v.yday != 0 && t.YearDay() != v.yday:
+		return time.Time{}, fmt.Errorf("day of year %d out of range", v.yday)
+	case v.ordinal != "" && !strings.EqualFold(v.ordinal, ordinalSuffix(t.Day())):
+		return time.Time{}, fmt.Errorf("wrong ordinal suffix %q for day %d", v.ordinal, t.Day())
+	}
+	if err := v.fiscal.check(t, start); err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// layoutElement holds an element of a layout
+// and the text that it matched.
+type layoutElement struct {
+	std  int
+	text string
+}
+
+// splitLayout matches value against layout. It returns the elements of
+// the layout for which extract returns true, and the layout and value
+// with those elements removed, which can be parsed by the time package.
+// It reports false if the value does not match the layout.
+func (s Style) splitLayout(layout, value string, extract func(std int) bool) (elems []layoutElement, restLayout, restValue string, ok bool) {
+	re, err := compileLayout("^" + s.layoutPattern(layout, true) + "$")
+	if err != nil {
+		return nil, "", "", false
+	}
+	m := re.FindStringSubmatchIndex(value)
+	if m == nil {
+		return nil, "", "", false
+	}
+	var rl, rv strings.Builder
+	end := 0
+	for group := 1; layout != ""; group++ {
+		prefix, std, suffix := nextStdChunk(layout)
+		rl.WriteString(prefix)
+		if std == 0 {
+			break
+		}
+		chunk := layout[len(prefix) : len(layout)-len(suffix)]
+		layout = suffix
+		start := m[2*group]
+		rv.WriteString(value[end:start])
+		end = m[2*group+1]
+		text := value[start:end]
+		if extract(std) {
+			elems = append(elems, layoutElement{std, text})
+		} else {
+			rl.WriteString(chunk)
+			rv.WriteString(text)
+		}
+	}
+	rv.WriteString(value[end:])
+	return elems, rl.String(), rv.String(), true
+}
+
+// layoutRegexps holds the regular expressions compiled by
+// compileLayout, keyed by pattern.
+var layoutRegexps sync.Map // map[string]*regexp.Regexp
+
+// compileLayout is like regexp.Compile except that it reuses
+// expressions compiled earlier, so that the same layout can be
+// parsed repeatedly without compiling its pattern each time.
+func compileLayout(pat string) (*regexp.Regexp, error) {
+	if re, ok := layoutRegexps.Load(pat); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return nil, err
+	}
+	layoutRegexps.Store(pat, re)
+	return re, nil
+}
@@ -0,0 +1,108 @@
+package timeformat
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var extensionTests = []struct {
+	layout string
+	time   time.Time
+	value  string
+	// parsed holds the time that value parses as,
+	// if it's different from time.
+	parsed time.Time
+}{{
+	layout: "%G-W%V-%u",
+	time:   time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC),
+	value:  "2020-W53-7",
+}, {
+	layout: "%G-W%V",
+	time:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+	value:  "2025-W01",
+	parsed: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+}, {
+	layout: "2006-01-02 %w %u",
+	time:   time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+	value:  "2024-03-10 0 7",
+}, {
+	layout: "2006.%j",
+	time:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+	value:  "2024.366",
+}, {
+	layout: "2006-01-02 %ipm",
+	time:   time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC),
+	value:  "2024-03-10 3pm",
+}, {
+	layout: "2006-01-02 %i PM",
+	time:   time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC),
+	value:  "2024-03-10 12:30 AM",
+}, {
+	layout: "2006-01-02 %i",
+	time:   time.Date(2024, 3, 10, 11, 5, 0, 0, time.UTC),
+	value:  "2024-03-10 11:05",
+}, {
+	layout: "January 2%o, 2006",
+	time:   time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+	value:  "March 3rd, 2024",
+}, {
+	layout: "Monday 2%o January",
+	time:   time.Date(0, 2, 11, 0, 0, 0, 0, time.UTC),
+	value:  "Friday 11th February",
+}, {
+	layout: "2%o Jan 2006",
+	time:   time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC),
+	value:  "22nd Jan 2024",
+}, {
+	layout: `Report \1 of 2006`,
+	time:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	value:  "Report 1 of 2024",
+}, {
+	layout: `\Monday: Monday \P\M 3PM \\ \%Q \é`,
+	time:   time.Date(0, 1, 1, 15, 0, 0, 0, time.UTC),
+	value:  `Monday: Saturday PM 3PM \ %Q é`,
+}}
+
+func TestExtensions(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range extensionTests {
+		c.Run(test.layout+"/"+test.value, func(c *qt.C) {
+			got, err := Style{}.Format(test.time, test.layout)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, test.value)
+			tm, err := Style{}.Parse(test.layout, test.value)
+			c.Assert(err, qt.IsNil)
+			expect := test.parsed
+			if expect.IsZero() {
+				expect = test.time
+			}
+			c.Assert(tm, qt.Equals, expect)
+		})
+	}
+}
+
+func TestOrdinalSuffix(t *testing.T) {
+	c := qt.New(t)
+	expect := []string{"th", "st", "nd", "rd", "th", "th", "th", "th", "th", "th"}
+	for n := 0; n < 130; n++ {
+		want := expect[n%10]
+		if n%100 >= 11 && n%100 <= 13 {
+			want = "th"
+		}
+		c.Assert(ordinalSuffix(n), qt.Equals, want, qt.Commentf("%d", n))
+	}
+}
+
+func TestExtensionParseErrors(t *testing.T) {
+	c := qt.New(t)
+	_, err := Style{}.Parse("January 2%o", "March 3th")
+	c.Assert(err, qt.ErrorMatches, `wrong ordinal suffix "th" for day 3`)
+	_, err = Style{}.Parse("2006-01-02 %u", "2024-03-10 1")
+	c.Assert(err, qt.ErrorMatches, `date is not on weekday 1`)
+	_, err = Style{}.Parse("2006.%j", "2023.366")
+	c.Assert(err, qt.ErrorMatches, `day of year 366 out of range`)
+	_, err = Style{}.Parse("%G-W%V", "2024-W53")
+	c.Assert(err, qt.ErrorMatches, `ISO week 53 out of range in 2024`)
+}
@@ -0,0 +1,86 @@
+package timeformat
+
+import (
+	"fmt"
+	"time"
+)
+
+// A fiscal year is named after the calendar year in which it ends,
+// so when it starts in October, FY2025 runs from October 2024 to
+// September 2025. Fiscal quarters and weeks are counted from the
+// start of the fiscal year. They are always calculated in the Gregorian
+// calendar.
+
+func (s Style) fiscalStart() time.Month {
+	if s.FiscalStart == 0 {
+		return time.January
+	}
+	return s.FiscalStart
+}
+
+// fiscalYear returns the fiscal year containing t.
+func fiscalYear(t time.Time, start time.Month) int {
+	if start > time.January && t.Month() >= start {
+		return t.Year() + 1
+	}
+	return t.Year()
+}
+
+// fiscalYearStart returns the first day of the given fiscal year.
+func fiscalYearStart(year int, start time.Month) (int, time.Month, int) {
+	if start > time.January {
+		year--
+	}
+	return year, start, 1
+}
+
+// fiscalQuarter returns the quarter of the fiscal year containing t,
+// from 1 to 4.
+func fiscalQuarter(t time.Time, start time.Month) int {
+	return floorMod(int(t.Month()-start), 12)/3 + 1
+}
+
+// fiscalWeek returns the week of the fiscal year containing t.
+// Week 1 starts on the first day of the fiscal year, whatever
+// day of the week that is, and the last week may be short.
+func fiscalWeek(t time.Time, start time.Month) int {
+	first := dayFromGregorian(fiscalYearStart(fiscalYear(t, start), start))
+	return (dayFromGregorian(t.Date())-first)/7 + 1
+}
+
+// fiscalPeriod holds the fiscal elements parsed from a time.
+// Zero fields were not present.
+type fiscalPeriod struct {
+	year    int
+	quarter int
+	week    int
+}
+
+// date returns the first day of the period p. If p has no fiscal year,
+// the given year is used.
+func (p fiscalPeriod) date(year int, start time.Month) (int, time.Month, int) {
+	if p.year != 0 {
+		year = p.year
+	}
+	y, m, d := fiscalYearStart(year, start)
+	switch {
+	case p.week > 0:
+		d += (p.week - 1) * 7
+	case p.quarter > 0:
+		m += time.Month(p.quarter-1) * 3
+	}
+	return y, m, d
+}
+
+// check returns an error if t is not inside the period p.
+func (p fiscalPeriod) check(t time.Time, start time.Month) error {
+	switch {
+	case p.year != 0 && fiscalYear(t, start) != p.year:
+		return fmt.Errorf("date is not in fiscal year %d", p.year)
+	case p.quarter != 0 && fiscalQuarter(t, start) != p.quarter:
+		return fmt.Errorf("date is not in fiscal quarter %d", p.quarter)
+	case p.week != 0 && fiscalWeek(t, start) != p.week:
+		return fmt.Errorf("date is not in fiscal week %d", p.week)
+	}
+	return nil
+}
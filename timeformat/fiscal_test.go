@@ -0,0 +1,100 @@
+package timeformat
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var fiscalTests = []struct {
+	start  time.Month
+	layout string
+	time   time.Time
+	value  string
+	// parsed holds the time that value parses as,
+	// if it's different from time.
+	parsed time.Time
+}{{
+	layout: "2006Q%Q",
+	time:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	value:  "2024Q3",
+}, {
+	layout: "2006Q%Q",
+	time:   time.Date(2024, 9, 30, 12, 0, 0, 0, time.UTC),
+	value:  "2024Q3",
+	parsed: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	start:  time.October,
+	layout: "FY%f Q%Q",
+	time:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+	value:  "FY25 Q1",
+}, {
+	start:  time.October,
+	layout: "FY%F Q%Q",
+	time:   time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC),
+	value:  "FY2025 Q4",
+	parsed: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	start:  time.April,
+	layout: "FY%F",
+	time:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	value:  "FY2024",
+	parsed: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	start:  time.July,
+	layout: "FY%F W%W",
+	time:   time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+	value:  "FY2025 W02",
+}, {
+	layout: "%F-W%W 15:04",
+	time:   time.Date(2024, 12, 31, 9, 30, 0, 0, time.UTC),
+	value:  "2024-W53 09:30",
+	parsed: time.Date(2024, 12, 30, 9, 30, 0, 0, time.UTC),
+}, {
+	start:  time.October,
+	layout: "2006-01-02 FY%F Q%Q",
+	time:   time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC),
+	value:  "2024-11-05 FY2025 Q1",
+}, {
+	layout: "Q%Q: %%",
+	time:   time.Date(0, 4, 1, 0, 0, 0, 0, time.UTC),
+	value:  "Q2: %",
+}}
+
+func TestFiscal(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range fiscalTests {
+		c.Run(test.layout+"/"+test.value, func(c *qt.C) {
+			s := Style{FiscalStart: test.start}
+			got, err := s.Format(test.time, test.layout)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, test.value)
+			tm, err := s.Parse(test.layout, test.value)
+			c.Assert(err, qt.IsNil)
+			expect := test.parsed
+			if expect.IsZero() {
+				expect = test.time
+			}
+			c.Assert(tm, qt.Equals, expect)
+		})
+	}
+}
+
+func TestFiscalParseErrors(t *testing.T) {
+	c := qt.New(t)
+	s := Style{FiscalStart: time.October}
+	_, err := s.Parse("2006-01-02 Q%Q", "2024-11-05 Q2")
+	c.Assert(err, qt.ErrorMatches, `date is not in fiscal quarter 2`)
+	_, err = s.Parse("2006-01-02 FY%F", "2024-11-05 FY2024")
+	c.Assert(err, qt.ErrorMatches, `date is not in fiscal year 2024`)
+	_, err = s.Parse("FY%F W%W", "FY2025 W60")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "FY2025 W60" as "FY%F W%W"`)
+	_, err = s.Parse("FY%F Q%Q", "FY2025 Q5")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "FY2025 Q5" as "FY%F Q%Q"`)
+	cal, err := LookupCalendar("persian")
+	c.Assert(err, qt.IsNil)
+	s.Calendar = cal
+	_, err = s.Parse("2006-01-02 Q%Q", "1403-01-01 Q3")
+	c.Assert(err, qt.ErrorMatches, `%-elements are not supported when parsing in the persian calendar`)
+}
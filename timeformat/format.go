@@ -62,9 +62,13 @@ func (c Components) String() string {
 	return buf.String()
 }
 
-// Date specifies a date. Each field corresponds to one argument
-// to the time.Date function.
+// Date specifies a date. Each field apart from Era corresponds to one
+// argument to the time.Date function.
 type Date struct {
+	// Era holds the era of the year, for calendars
+	// that number their years from the start of each
+	// era (see Calendar). It is empty for Gregorian dates.
+	Era        string
 	Year       int
 	Month      time.Month
 	Day        int
@@ -80,6 +84,7 @@ type Date struct {
 // The location is set if either TZOffset or TZName are present.
 func (d *Date) SetComponents(d1 *Date, which Components) {
 	if which&Year != 0 {
+		d.Era = d1.Era
 		d.Year = d1.Year
 	}
 	if which&Month != 0 {
@@ -163,6 +168,20 @@ var stdComponents = []Components{
 	stdNumColonSecondsTZ:     TZOffset,
 	stdFracSecond0:           0,
 	stdFracSecond9:           0,
+	stdEscape:                0,
+	stdPercent:               0,
+	stdQuarter:               Month,
+	stdFiscalYear:            Year,
+	stdFiscalYear2:           Year,
+	// A week determines both the month and the day.
+	stdFiscalWeek: Month | Day,
+	stdISOWeek:    Month | Day,
+	stdISOYear:    Year,
+	stdISOWeekday: 0,
+	stdNumWeekday: 0,
+	stdYearDay:    Day,
+	stdSmartHour:  Hour | Minute,
+	stdOrdinal:    0,
 }
 
 // LayoutComponents returns a bitmask of all the format
@@ -175,7 +194,7 @@ func LayoutComponents(layout string) Components {
 			break
 		}
 		layout = suffix
-		c |= stdComponents[std]
+		c |= stdComponents[std&stdMask]
 	}
 	return c
 }
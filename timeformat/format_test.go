@@ -28,6 +28,39 @@ var layoutComponentsTests = []struct {
 }, {
 	layout:     "2006 -07:00",
 	components: Year | TZOffset,
+}, {
+	layout:     time.RFC3339Nano,
+	components: Year | Month | Day | Hour | Minute | Second | TZOffset,
+}, {
+	layout:     "FY%f Q%Q",
+	components: Year | Month,
+}, {
+	layout:     "Q%Q",
+	components: Month,
+}, {
+	layout:     "%F-W%W",
+	components: Year | Month | Day,
+}, {
+	layout:     "100%% 15:04",
+	components: Month | Hour | Minute,
+}, {
+	layout:     "%G-W%V-%u",
+	components: Year | Month | Day,
+}, {
+	layout:     "2006.%j",
+	components: Year | Day,
+}, {
+	layout:     "Monday %ipm",
+	components: Hour | Minute,
+}, {
+	layout:     "January 2%o",
+	components: Month | Day,
+}, {
+	layout:     `Report \1 of 2006`,
+	components: Year,
+}, {
+	layout:     `\2\0\0\6-01`,
+	components: Month,
 }}
 
 func TestLayoutComponents(t *testing.T) {
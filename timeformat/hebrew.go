@@ -0,0 +1,167 @@
+package timeformat
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	hebrewMonths = []string{
+		"Tishrei", "Heshvan", "Kislev", "Tevet", "Shevat", "Adar",
+		"Nisan", "Iyar", "Sivan", "Tammuz", "Av", "Elul",
+	}
+	hebrewLeapMonths = []string{
+		"Tishrei", "Heshvan", "Kislev", "Tevet", "Shevat", "Adar I", "Adar II",
+		"Nisan", "Iyar", "Sivan", "Tammuz", "Av", "Elul",
+	}
+)
+
+// hebrewEpoch holds the day number of 1 Tishrei AM 1
+// (7th October 3761 BCE in the Julian calendar).
+const hebrewEpoch = -1373427
+
+// hebrewCalendar implements Calendar for the Hebrew calendar.
+// Months are numbered from Tishrei, the first month of the civil year,
+// so in a leap year month 6 is Adar I and month 7 is Adar II, and in
+// other years month 6 is Adar and month 7 is Nisan.
+//
+// The calculations follow Dershowitz and Reingold's Calendrical
+// Calculations, which numbers months from Nisan.
+type hebrewCalendar struct{}
+
+func (hebrewCalendar) Name() string { return "hebrew" }
+
+func (hebrewCalendar) FromGregorian(d *Date) (*Date, error) {
+	n := dayFromGregorian(d.Year, d.Month, d.Day)
+	if n < hebrewEpoch {
+		return nil, fmt.Errorf("date is before the start of the Hebrew calendar")
+	}
+	// Start with an estimate based on the mean year length.
+	year := floorDiv((n-hebrewEpoch)*98496, 35975351)
+	for hebrewNewYear(year+1) <= n {
+		year++
+	}
+	month := 1
+	for n >= hebrewNewYear(year)+hebrewDaysBefore(year, month+1) && month < hebrewMonthsIn(year) {
+		month++
+	}
+	day := n - hebrewNewYear(year) - hebrewDaysBefore(year, month) + 1
+	return withDate(d, "", year, time.Month(month), day), nil
+}
+
+func (cal hebrewCalendar) ToGregorian(d *Date) (*Date, error) {
+	if d.Year < 1 {
+		return nil, fmt.Errorf("year %d out of range in hebrew calendar", d.Year)
+	}
+	err := checkDate(cal, d, hebrewMonthsIn(d.Year), func() int {
+		return hebrewDaysIn(d.Year, int(d.Month))
+	})
+	if err != nil {
+		return nil, err
+	}
+	n := hebrewNewYear(d.Year) + hebrewDaysBefore(d.Year, int(d.Month)) + d.Day - 1
+	year, month, day := gregorianFromDay(n)
+	return withDate(d, "", year, month, day), nil
+}
+
+func (hebrewCalendar) MonthNames(year int) []string {
+	if hebrewLeapYear(year) {
+		return hebrewLeapMonths
+	}
+	return hebrewMonths
+}
+
+func (hebrewCalendar) Eras() []string {
+	return nil
+}
+
+func hebrewLeapYear(year int) bool {
+	return floorMod(7*year+1, 19) < 7
+}
+
+func hebrewMonthsIn(year int) int {
+	if hebrewLeapYear(year) {
+		return 13
+	}
+	return 12
+}
+
+// hebrewElapsedDays returns the number of days from the epoch
+// to the molad (mean conjunction) of Tishrei of the given year,
+// postponed if the molad falls on a Sunday, Wednesday or Friday.
+func hebrewElapsedDays(year int) int {
+	months := floorDiv(235*year-234, 19)
+	parts := 12084 + 13753*months
+	day := 29*months + floorDiv(parts, 25920)
+	if floorMod(3*(day+1), 7) < 3 {
+		day++
+	}
+	return day
+}
+
+// hebrewNewYear returns the day number of 1 Tishrei in the given year.
+func hebrewNewYear(year int) int {
+	ny0 := hebrewElapsedDays(year - 1)
+	ny1 := hebrewElapsedDays(year)
+	ny2 := hebrewElapsedDays(year + 1)
+	// Postpone the new year if it would
+	// make the year the wrong length.
+	delay := 0
+	switch {
+	case ny2-ny1 == 356:
+		delay = 2
+	case ny1-ny0 == 382:
+		delay = 1
+	}
+	return hebrewEpoch + ny1 + delay
+}
+
+// hebrewDaysIn returns the number of days in the given
+// month, numbered from Tishrei.
+func hebrewDaysIn(year, month int) int {
+	yearLen := hebrewNewYear(year+1) - hebrewNewYear(year)
+	leap := hebrewLeapYear(year)
+	switch {
+	case month == 2:
+		// Heshvan is long in complete years.
+		if yearLen%10 == 5 {
+			return 30
+		}
+		return 29
+	case month == 3:
+		// Kislev is short in deficient years.
+		if yearLen%10 == 3 {
+			return 29
+		}
+		return 30
+	case month == 4:
+		return 29
+	case month == 6 && !leap:
+		return 29
+	}
+	if leap {
+		switch {
+		case month == 7:
+			// Adar II.
+			return 29
+		case month > 7:
+			month--
+		}
+	}
+	if month > 6 {
+		// Nisan has 30 days, Iyar 29, and so on.
+		return 30 - (month-7)%2
+	}
+	// Tishrei, Shevat and Adar I have 30 days.
+	return 30
+}
+
+// hebrewDaysBefore returns the number of days in the given
+// year before the start of the given month.
+func hebrewDaysBefore(year, month int) int {
+	n := 0
+	for m := 1; m < month; m++ {
+		n += hebrewDaysIn(year, m)
+	}
+	return n
+}
@@ -0,0 +1,126 @@
+package timeformat
+
+import (
+	"fmt"
+	"time"
+)
+
+var islamicMonths = []string{
+	"Muharram", "Safar", "Rabi al-Awwal", "Rabi al-Thani", "Jumada al-Ula", "Jumada al-Akhirah",
+	"Rajab", "Shaban", "Ramadan", "Shawwal", "Dhu al-Qadah", "Dhu al-Hijjah",
+}
+
+// islamicEpoch holds the day number of 1 Muharram 1 AH
+// (16th July 622 in the Julian calendar).
+const islamicEpoch = 227015
+
+// islamicCalendar implements Calendar for the tabular (civil) Islamic
+// calendar, in which 11 years in each 30 year cycle are leap years.
+type islamicCalendar struct{}
+
+func (islamicCalendar) Name() string { return "islamic" }
+
+func (islamicCalendar) FromGregorian(d *Date) (*Date, error) {
+	n := dayFromGregorian(d.Year, d.Month, d.Day)
+	if n < islamicEpoch {
+		return nil, fmt.Errorf("date is before the start of the Islamic calendar")
+	}
+	year := floorDiv(30*(n-islamicEpoch)+10646, 10631)
+	month := floorDiv(11*(n-dayFromIslamic(year, 1, 1))+330, 325)
+	day := n - dayFromIslamic(year, time.Month(month), 1) + 1
+	return withDate(d, "", year, time.Month(month), day), nil
+}
+
+func (cal islamicCalendar) ToGregorian(d *Date) (*Date, error) {
+	if d.Year < 1 {
+		return nil, fmt.Errorf("year %d out of range in islamic calendar", d.Year)
+	}
+	err := checkDate(cal, d, 12, func() int {
+		switch {
+		case d.Month%2 == 1:
+			return 30
+		case d.Month == 12 && floorMod(14+11*d.Year, 30) < 11:
+			// Leap years have an extra day at the end.
+			return 30
+		}
+		return 29
+	})
+	if err != nil {
+		return nil, err
+	}
+	year, month, day := gregorianFromDay(dayFromIslamic(d.Year, d.Month, d.Day))
+	return withDate(d, "", year, month, day), nil
+}
+
+func (islamicCalendar) MonthNames(year int) []string {
+	return islamicMonths
+}
+
+func (islamicCalendar) Eras() []string {
+	return nil
+}
+
+// dayFromIslamic returns the day number of the given date
+// in the tabular Islamic calendar.
+func dayFromIslamic(year int, month time.Month, day int) int {
+	return islamicEpoch - 1 +
+		(year-1)*354 +
+		floorDiv(3+11*year, 30) +
+		29*(int(month)-1) +
+		int(month)/2 +
+		day
+}
+
+// mcjdEpoch holds the day number of the epoch of the
+// modified chronological Julian day numbers used by
+// ummAlQuraMonthStarts (1858-11-16).
+const mcjdEpoch = 678575
+
+// ummAlQuraCalendar implements Calendar for the Umm al-Qura
+// calendar used in Saudi Arabia, which is based on astronomical
+// calculations. Only dates from 1356 to 1500 AH (1937 to 2077)
+// are supported.
+type ummAlQuraCalendar struct{}
+
+func (ummAlQuraCalendar) Name() string { return "umalqura" }
+
+func (ummAlQuraCalendar) FromGregorian(d *Date) (*Date, error) {
+	n := dayFromGregorian(d.Year, d.Month, d.Day) - mcjdEpoch
+	starts := ummAlQuraMonthStarts
+	if n < starts[0] || n >= starts[len(starts)-1] {
+		return nil, fmt.Errorf("date is outside the range of the Umm al-Qura calendar")
+	}
+	// Find the last month starting on or before n.
+	i := 0
+	for j := len(starts) - 1; j > 0; j /= 2 {
+		for i+j < len(starts) && starts[i+j] <= n {
+			i += j
+		}
+	}
+	lunation := i + ummAlQuraFirstLunation - 1
+	return withDate(d, "", lunation/12+1, time.Month(lunation%12+1), n-starts[i]+1), nil
+}
+
+func (cal ummAlQuraCalendar) ToGregorian(d *Date) (*Date, error) {
+	starts := ummAlQuraMonthStarts
+	i := (d.Year-1)*12 + int(d.Month) - ummAlQuraFirstLunation
+	if d.Month < 1 || d.Month > 12 || i < 0 || i >= len(starts)-1 {
+		return nil, fmt.Errorf("date is outside the range of the Umm al-Qura calendar")
+	}
+	err := checkDate(cal, d, 12, func() int {
+		return starts[i+1] - starts[i]
+	})
+	if err != nil {
+		return nil, err
+	}
+	year, month, day := gregorianFromDay(starts[i] + d.Day - 1 + mcjdEpoch)
+	return withDate(d, "", year, month, day), nil
+}
+
+func (ummAlQuraCalendar) MonthNames(year int) []string {
+	return islamicMonths
+}
+
+func (ummAlQuraCalendar) Eras() []string {
+	return nil
+}
@@ -0,0 +1,376 @@
+package timeformat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Locale holds the names used for months, weekdays and
+// the AM/PM markers when formatting and parsing times
+// in a particular language.
+type Locale struct {
+	// Name holds the name of the locale, for example "fr".
+	Name string
+	// Months holds the full month names, starting with January.
+	Months [12]string
+	// ShortMonths holds the abbreviated month names.
+	ShortMonths [12]string
+	// Days holds the full weekday names, starting with Sunday.
+	Days [7]string
+	// ShortDays holds the abbreviated weekday names.
+	ShortDays [7]string
+	// AM and PM hold the markers for times before and after noon.
+	AM, PM string
+}
+
+// English holds the locale used by the time package.
+var English = &Locale{
+	Name: "en",
+	Months: [12]string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	},
+	ShortMonths: [12]string{
+		"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+	},
+	Days: [7]string{
+		"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+	},
+	ShortDays: [7]string{
+		"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat",
+	},
+	AM: "AM",
+	PM: "PM",
+}
+
+// locales holds all the known locales, keyed by name.
+// The names are taken from the CLDR "format" wide and
+// abbreviated forms.
+var locales = map[string]*Locale{
+	"en": English,
+	"de": {
+		Name: "de",
+		Months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		ShortMonths: [12]string{
+			"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni",
+			"Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez.",
+		},
+		Days: [7]string{
+			"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag",
+		},
+		ShortDays: [7]string{
+			"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa.",
+		},
+		AM: "AM",
+		PM: "PM",
+	},
+	"es": {
+		Name: "es",
+		Months: [12]string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		ShortMonths: [12]string{
+			"ene", "feb", "mar", "abr", "may", "jun",
+			"jul", "ago", "sept", "oct", "nov", "dic",
+		},
+		Days: [7]string{
+			"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado",
+		},
+		ShortDays: [7]string{
+			"dom", "lun", "mar", "mié", "jue", "vie", "sáb",
+		},
+		AM: "a. m.",
+		PM: "p. m.",
+	},
+	"fr": {
+		Name: "fr",
+		Months: [12]string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		ShortMonths: [12]string{
+			"janv.", "févr.", "mars", "avr.", "mai", "juin",
+			"juil.", "août", "sept.", "oct.", "nov.", "déc.",
+		},
+		Days: [7]string{
+			"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi",
+		},
+		ShortDays: [7]string{
+			"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam.",
+		},
+		AM: "AM",
+		PM: "PM",
+	},
+	"it": {
+		Name: "it",
+		Months: [12]string{
+			"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre",
+		},
+		ShortMonths: [12]string{
+			"gen", "feb", "mar", "apr", "mag", "giu",
+			"lug", "ago", "set", "ott", "nov", "dic",
+		},
+		Days: [7]string{
+			"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato",
+		},
+		ShortDays: [7]string{
+			"dom", "lun", "mar", "mer", "gio", "ven", "sab",
+		},
+		AM: "AM",
+		PM: "PM",
+	},
+	"nl": {
+		Name: "nl",
+		Months: [12]string{
+			"januari", "februari", "maart", "april", "mei", "juni",
+			"juli", "augustus", "september", "oktober", "november", "december",
+		},
+		ShortMonths: [12]string{
+			"jan.", "feb.", "mrt.", "apr.", "mei", "jun.",
+			"jul.", "aug.", "sep.", "okt.", "nov.", "dec.",
+		},
+		Days: [7]string{
+			"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag",
+		},
+		ShortDays: [7]string{
+			"zo", "ma", "di", "wo", "do", "vr", "za",
+		},
+		AM: "a.m.",
+		PM: "p.m.",
+	},
+	"pt": {
+		Name: "pt",
+		Months: [12]string{
+			"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+		},
+		ShortMonths: [12]string{
+			"jan.", "fev.", "mar.", "abr.", "mai.", "jun.",
+			"jul.", "ago.", "set.", "out.", "nov.", "dez.",
+		},
+		Days: [7]string{
+			"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado",
+		},
+		ShortDays: [7]string{
+			"dom.", "seg.", "ter.", "qua.", "qui.", "sex.", "sáb.",
+		},
+		AM: "AM",
+		PM: "PM",
+	},
+	"sv": {
+		Name: "sv",
+		Months: [12]string{
+			"januari", "februari", "mars", "april", "maj", "juni",
+			"juli", "augusti", "september", "oktober", "november", "december",
+		},
+		ShortMonths: [12]string{
+			"jan.", "feb.", "mars", "apr.", "maj", "juni",
+			"juli", "aug.", "sep.", "okt.", "nov.", "dec.",
+		},
+		Days: [7]string{
+			"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag",
+		},
+		ShortDays: [7]string{
+			"sön", "mån", "tis", "ons", "tors", "fre", "lör",
+		},
+		AM: "fm",
+		PM: "em",
+	},
+}
+
+// LookupLocale returns the locale with the given name. As well as
+// plain language names such as "de", it accepts names with a territory
+// or encoding such as "de_DE.UTF-8" or "de-AT", which are treated
+// as the plain language.
+func LookupLocale(name string) (*Locale, error) {
+	lang := strings.ToLower(name)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "c" || lang == "posix" {
+		return English, nil
+	}
+	l, ok := locales[lang]
+	if !ok {
+		return nil, fmt.Errorf("unknown locale %q", name)
+	}
+	return l, nil
+}
+
+// LocaleNames returns the names of all the known locales in
+// alphabetical order.
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format is like time.Time.Format except that month and
+// weekday names and AM/PM markers are written in the locale's
+// language.
+func (l *Locale) Format(t time.Time, layout string) string {
+	if hasExtensions(layout) {
+		// Style.Format only returns an error for calendars.
+		s, _ := Style{Locale: l}.Format(t, layout)
+		return s
+	}
+	if l == English {
+		return t.Format(layout)
+	}
+	var buf strings.Builder
+	for layout != "" {
+		prefix, std, suffix := nextStdChunk(layout)
+		buf.WriteString(prefix)
+		if std == 0 {
+			break
+		}
+		chunk := layout[len(prefix) : len(layout)-len(suffix)]
+		layout = suffix
+		switch std & stdMask {
+		case stdLongMonth:
+			buf.WriteString(l.Months[t.Month()-1])
+		case stdMonth:
+			buf.WriteString(l.ShortMonths[t.Month()-1])
+		case stdLongWeekDay:
+			buf.WriteString(l.Days[t.Weekday()])
+		case stdWeekDay:
+			buf.WriteString(l.ShortDays[t.Weekday()])
+		case stdPM, stdpm:
+			marker := l.AM
+			if t.Hour() >= 12 {
+				marker = l.PM
+			}
+			if std == stdpm {
+				marker = strings.ToLower(marker)
+			}
+			buf.WriteString(marker)
+		default:
+			// All the other elements are formatted independently
+			// of one another, so we can use the time package
+			// to format them one at a time.
+			buf.WriteString(t.Format(chunk))
+		}
+	}
+	return buf.String()
+}
+
+// Parse is like time.Parse except that it accepts month and weekday
+// names and AM/PM markers in the locale's language as well as in English.
+func (l *Locale) Parse(layout, value string) (time.Time, error) {
+	return l.ParseInLocation(layout, value, time.UTC)
+}
+
+// ParseInLocation is like time.ParseInLocation except that it accepts
+// month and weekday names and AM/PM markers in the locale's language
+// as well as in English.
+func (l *Locale) ParseInLocation(layout, value string, loc *time.Location) (time.Time, error) {
+	if hasExtensions(layout) {
+		return Style{Locale: l}.ParseInLocation(layout, value, loc)
+	}
+	if l != English {
+		value = l.translate(layout, value)
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// nameReplacement holds a name in a locale's language
+// and the English text to replace it with.
+type nameReplacement struct {
+	name, english string
+}
+
+// translate returns value with the locale's names replaced by
+// the English names expected by the given layout. Names are
+// only replaced when they are not part of a larger word.
+func (l *Locale) translate(layout, value string) string {
+	var repls []nameReplacement
+	add := func(names []string, english []string) {
+		for i, name := range names {
+			repls = append(repls, nameReplacement{name, english[i]})
+			if trimmed := strings.TrimSuffix(name, "."); trimmed != name {
+				repls = append(repls, nameReplacement{trimmed, english[i]})
+			}
+		}
+	}
+	for layout != "" {
+		_, std, suffix := nextStdChunk(layout)
+		if std == 0 {
+			break
+		}
+		layout = suffix
+		switch std & stdMask {
+		case stdLongMonth:
+			add(l.Months[:], English.Months[:])
+			add(l.ShortMonths[:], English.Months[:])
+		case stdMonth:
+			add(l.Months[:], English.ShortMonths[:])
+			add(l.ShortMonths[:], English.ShortMonths[:])
+		case stdLongWeekDay:
+			add(l.Days[:], English.Days[:])
+			add(l.ShortDays[:], English.Days[:])
+		case stdWeekDay:
+			add(l.Days[:], English.ShortDays[:])
+			add(l.ShortDays[:], English.ShortDays[:])
+		case stdPM, stdpm:
+			english := []string{"AM", "PM"}
+			if std == stdpm {
+				english = []string{"am", "pm"}
+			}
+			add([]string{l.AM, l.PM}, english)
+		}
+	}
+	if len(repls) == 0 {
+		return value
+	}
+	// Try the longest names first so that, for example,
+	// "mars" is preferred over "mar".
+	sort.SliceStable(repls, func(i, j int) bool {
+		return len(repls[i].name) > len(repls[j].name)
+	})
+	var buf strings.Builder
+	prevLetter := false
+	for i := 0; i < len(value); {
+		if !prevLetter {
+			if r, ok := matchName(value[i:], repls); ok {
+				buf.WriteString(r.english)
+				i += len(r.name)
+				prevLetter = true
+				continue
+			}
+		}
+		c, size := utf8.DecodeRuneInString(value[i:])
+		buf.WriteString(value[i : i+size])
+		prevLetter = unicode.IsLetter(c)
+		i += size
+	}
+	return buf.String()
+}
+
+// matchName returns the replacement whose name is a case-insensitive
+// prefix of s that is not immediately followed by a letter.
+func matchName(s string, repls []nameReplacement) (nameReplacement, bool) {
+	for _, r := range repls {
+		n := len(r.name)
+		if n == 0 || n > len(s) || !strings.EqualFold(s[:n], r.name) {
+			continue
+		}
+		if c, _ := utf8.DecodeRuneInString(s[n:]); n < len(s) && unicode.IsLetter(c) {
+			continue
+		}
+		return r, true
+	}
+	return nameReplacement{}, false
+}
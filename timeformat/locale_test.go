@@ -0,0 +1,136 @@
+package timeformat
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var localeFormatTests = []struct {
+	locale string
+	layout string
+	expect string
+}{{
+	locale: "en",
+	layout: "Monday 2 January 2006 3:04pm",
+	expect: "Tuesday 5 March 2024 2:30pm",
+}, {
+	locale: "fr",
+	layout: "Monday 2 January 2006",
+	expect: "mardi 5 mars 2024",
+}, {
+	locale: "fr_FR.UTF-8",
+	layout: "Mon 2 Jan 2006",
+	expect: "mar. 5 mars 2024",
+}, {
+	locale: "de",
+	layout: "Mon, 2. Jan 2006 15:04:05.000",
+	expect: "Di., 5. März 2024 14:30:00.000",
+}, {
+	locale: "sv",
+	layout: "3:04 PM",
+	expect: "2:30 em",
+}, {
+	locale: "es",
+	layout: "3:04 pm",
+	expect: "2:30 p. m.",
+}}
+
+func TestLocaleFormat(t *testing.T) {
+	c := qt.New(t)
+	tm := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+	for _, test := range localeFormatTests {
+		c.Run(test.locale+"/"+test.layout, func(c *qt.C) {
+			l, err := LookupLocale(test.locale)
+			c.Assert(err, qt.IsNil)
+			c.Assert(l.Format(tm, test.layout), qt.Equals, test.expect)
+		})
+	}
+}
+
+var localeParseTests = []struct {
+	locale      string
+	layout      string
+	value       string
+	expect      time.Time
+	expectError string
+}{{
+	locale: "fr",
+	layout: "2 Jan 2006",
+	value:  "5 mars 2024",
+	expect: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "fr",
+	layout: "2 Jan 2006",
+	value:  "5 Févr. 2024",
+	expect: time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "fr",
+	layout: "2 Jan 2006",
+	value:  "5 févr 2024",
+	expect: time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "fr",
+	layout: "Monday 2 January 2006",
+	value:  "mardi 5 mars 2024",
+	expect: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "de",
+	layout: "2. January 2006",
+	value:  "5. Dezember 2024",
+	expect: time.Date(2024, time.December, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "de",
+	layout: "2 Jan 2006",
+	value:  "5 Dec 2024",
+	expect: time.Date(2024, time.December, 5, 0, 0, 0, 0, time.UTC),
+}, {
+	locale: "sv",
+	layout: "3:04 PM",
+	value:  "2:30 em",
+	expect: time.Date(0, time.January, 1, 14, 30, 0, 0, time.UTC),
+}, {
+	locale:      "fr",
+	layout:      "2 Jan 2006",
+	value:       "5 marsx 2024",
+	expectError: `parsing time .*`,
+}}
+
+func TestLocaleParse(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range localeParseTests {
+		c.Run(test.locale+"/"+test.value, func(c *qt.C) {
+			l, err := LookupLocale(test.locale)
+			c.Assert(err, qt.IsNil)
+			tm, err := l.Parse(test.layout, test.value)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(tm, qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestLocaleLayoutPattern(t *testing.T) {
+	c := qt.New(t)
+	l, err := LookupLocale("fr")
+	c.Assert(err, qt.IsNil)
+	re := regexp.MustCompile("^(?:" + l.LayoutPattern("2 Jan 2006") + ")$")
+	for _, s := range []string{"5 mars 2024", "5 janv. 2024", "5 janv 2024", "5 Jan 2024"} {
+		c.Check(re.MatchString(s), qt.IsTrue, qt.Commentf("%q", s))
+	}
+	c.Check(re.MatchString("5 foo 2024"), qt.IsFalse)
+}
+
+func TestLookupLocale(t *testing.T) {
+	c := qt.New(t)
+	_, err := LookupLocale("xx")
+	c.Assert(err, qt.ErrorMatches, `unknown locale "xx"`)
+	l, err := LookupLocale("C")
+	c.Assert(err, qt.IsNil)
+	c.Assert(l, qt.Equals, English)
+}
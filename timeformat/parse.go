@@ -33,6 +33,8 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 
 package timeformat
 
+import "unicode/utf8"
+
 const (
 	_                        = iota
 	stdLongMonth             = iota + stdNeedDate  // "January"
@@ -205,6 +207,17 @@ func nextStdChunk(layout string) (prefix string, std int, suffix string) {
 				return layout[0:i], stdISO8601ShortTZ, layout[i+3:]
 			}
 
+		case '\\': // \x: a literal character (see extension.go)
+			if i+1 < len(layout) {
+				_, n := utf8.DecodeRuneInString(layout[i+1:])
+				return layout[0:i], stdEscape, layout[i+1+n:]
+			}
+
+		case '%': // %Q, %F and other extension elements (see extension.go)
+			if std, n := extensionStd(layout[i:]); std != 0 {
+				return layout[0:i], std, layout[i+n:]
+			}
+
 		case '.', ',': // ,000, or .000, or ,999, or .999 - repeated digits for fractional seconds.
 			if i+1 < len(layout) && (layout[i+1] == '0' || layout[i+1] == '9') {
 				ch := layout[i+1]
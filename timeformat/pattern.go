@@ -0,0 +1,169 @@
+package timeformat
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// namesPattern returns a case-insensitive pattern matching
+// any of the given names. Names ending in a period also
+// match without it.
+func namesPattern(names ...[]string) string {
+	var pats []string
+	for _, names := range names {
+		for _, name := range names {
+			pat := regexp.QuoteMeta(name)
+			if strings.HasSuffix(name, ".") {
+				pat = regexp.QuoteMeta(strings.TrimSuffix(name, ".")) + `\.?`
+			}
+			pats = append(pats, pat)
+		}
+	}
+	// Put longer names first so that they're
+	// preferred when there's a common prefix.
+	sort.SliceStable(pats, func(i, j int) bool {
+		return len(pats[i]) > len(pats[j])
+	})
+	return "(?i:" + strings.Join(pats, "|") + ")"
+}
+
+const secondFraction = `(?:[.,][0-9]+)?`
+
+var stdPatterns = map[int]string{
+	stdLongMonth:             namesPattern(English.Months[:]),
+	stdMonth:                 namesPattern(English.ShortMonths[:]),
+	stdNumMonth:              `1[0-2]|0?[1-9]`,
+	stdZeroMonth:             `0[1-9]|1[0-2]`,
+	stdLongWeekDay:           namesPattern(English.Days[:]),
+	stdWeekDay:               namesPattern(English.ShortDays[:]),
+	stdDay:                   `3[01]|[12][0-9]|0?[1-9]`,
+	stdUnderDay:              `3[01]|[12][0-9]| ?[1-9]`,
+	stdZeroDay:               `0[1-9]|[12][0-9]|3[01]`,
+	stdUnderYearDay:          `[ 0-9]{2}[0-9]`,
+	stdZeroYearDay:           `[0-9]{3}`,
+	stdHour:                  `2[0-3]|[01]?[0-9]`,
+	stdHour12:                `1[0-2]|0?[1-9]`,
+	stdZeroHour12:            `0[1-9]|1[0-2]`,
+	stdMinute:                `[0-5]?[0-9]`,
+	stdZeroMinute:            `[0-5][0-9]`,
+	stdSecond:                `[0-5]?[0-9]`,
+	stdZeroSecond:            `[0-5][0-9]`,
+	stdLongYear:              `[0-9]{4}`,
+	stdYear:                  `[0-9]{2}`,
+	stdPM:                    `[AP]M`,
+	stdpm:                    `[ap]m`,
+	stdTZ:                    `[A-Z][A-Za-z]{2,4}(?:[+-][0-9]{1,2})?|[+-][0-9]{2}(?:[0-9]{2})?`,
+	stdISO8601TZ:             `Z|[+-][0-9]{4}`,
+	stdISO8601SecondsTZ:      `Z|[+-][0-9]{6}`,
+	stdISO8601ShortTZ:        `Z|[+-][0-9]{2}`,
+	stdISO8601ColonTZ:        `Z|[+-][0-9]{2}:[0-9]{2}`,
+	stdISO8601ColonSecondsTZ: `Z|[+-][0-9]{2}:[0-9]{2}:[0-9]{2}`,
+	stdNumTZ:                 `[+-][0-9]{4}`,
+	stdNumSecondsTz:          `[+-][0-9]{6}`,
+	stdNumShortTZ:            `[+-][0-9]{2}`,
+	stdNumColonTZ:            `[+-][0-9]{2}:[0-9]{2}`,
+	stdNumColonSecondsTZ:     `[+-][0-9]{2}:[0-9]{2}:[0-9]{2}`,
+	stdFracSecond9:           secondFraction,
+	stdPercent:               `%`,
+	stdQuarter:               `[1-4]`,
+	stdFiscalYear:            `[0-9]{4}`,
+	stdFiscalYear2:           `[0-9]{2}`,
+	stdFiscalWeek:            `5[0-3]|[1-4][0-9]|0?[1-9]`,
+	stdISOWeek:               `5[0-3]|[1-4][0-9]|0?[1-9]`,
+	stdISOYear:               `[0-9]{4}`,
+	stdISOWeekday:            `[1-7]`,
+	stdNumWeekday:            `[0-6]`,
+	stdYearDay:               `[0-9]{3}`,
+	stdSmartHour:             `(?:1[0-2]|0?[1-9])(?::[0-5][0-9])?`,
+	stdOrdinal:               `(?i:st|nd|rd|th)`,
+}
+
+var spaces = regexp.MustCompile(` +`)
+
+// LayoutPattern returns a regular expression that matches
+// text that might be parsed by time.Parse with the given layout.
+// The expression is not anchored, and it may match some
+// text that does not parse successfully.
+func LayoutPattern(layout string) string {
+	return English.LayoutPattern(layout)
+}
+
+// LayoutPattern is like the LayoutPattern function except
+// that the expression matches text that might be parsed
+// by l.Parse.
+func (l *Locale) LayoutPattern(layout string) string {
+	return Style{Locale: l}.LayoutPattern(layout)
+}
+
+// LayoutPattern is like the LayoutPattern function except
+// that the expression matches text that might be parsed
+// by s.Parse.
+func (s Style) LayoutPattern(layout string) string {
+	return s.layoutPattern(layout, false)
+}
+
+// layoutPattern returns the pattern for the given layout.
+// If capture is true, the text matching each element of
+// the layout is captured by a group.
+func (s Style) layoutPattern(layout string, capture bool) string {
+	var buf strings.Builder
+	for layout != "" {
+		prefix, std, suffix := nextStdChunk(layout)
+		// As with time.Parse, a run of spaces in the layout
+		// matches one or more spaces in the value.
+		for i, lit := range spaces.Split(prefix, -1) {
+			if i > 0 {
+				buf.WriteString(" +")
+			}
+			buf.WriteString(regexp.QuoteMeta(lit))
+		}
+		if std == 0 {
+			break
+		}
+		chunk := layout[len(prefix) : len(layout)-len(suffix)]
+		layout = suffix
+		var pat string
+		switch std & stdMask {
+		case stdFracSecond0:
+			pat = fmt.Sprintf("[.,][0-9]{%d}", std>>stdArgShift)
+		case stdEscape:
+			pat = regexp.QuoteMeta(chunk[1:])
+		default:
+			pat = s.stdPattern(std)
+		}
+		pat = "(?:" + pat + ")"
+		switch std & stdMask {
+		case stdSecond, stdZeroSecond:
+			// Like time.Parse, allow a fractional second
+			// even when the layout doesn't specify one.
+			if _, next, _ := nextStdChunk(layout); next&stdMask != stdFracSecond0 && next&stdMask != stdFracSecond9 {
+				pat += secondFraction
+			}
+		}
+		if capture {
+			pat = "(" + pat + ")"
+		}
+		buf.WriteString(pat)
+	}
+	return buf.String()
+}
+
+// stdPattern returns the pattern for the given std value,
+// which is not stdFracSecond0.
+func (l *Locale) stdPattern(std int) string {
+	if l == English {
+		return stdPatterns[std&stdMask]
+	}
+	// Like l.Parse, accept both the locale's names and English names.
+	switch std & stdMask {
+	case stdLongMonth, stdMonth:
+		return namesPattern(l.Months[:], l.ShortMonths[:], English.Months[:], English.ShortMonths[:])
+	case stdLongWeekDay, stdWeekDay:
+		return namesPattern(l.Days[:], l.ShortDays[:], English.Days[:], English.ShortDays[:])
+	case stdPM, stdpm:
+		return namesPattern([]string{l.AM, l.PM}) + "|" + stdPatterns[std&stdMask]
+	}
+	return stdPatterns[std&stdMask]
+}
@@ -0,0 +1,76 @@
+package timeformat
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var layoutPatternTests = []struct {
+	layout   string
+	text     string
+	expectOK bool
+}{{
+	layout:   time.RFC3339,
+	text:     "2006-01-02T15:04:05Z",
+	expectOK: true,
+}, {
+	layout:   time.RFC3339,
+	text:     "2006-01-02T15:04:05.123+01:00",
+	expectOK: true,
+}, {
+	layout: time.RFC3339,
+	text:   "2006-01-02 15:04:05Z",
+}, {
+	layout:   time.Stamp,
+	text:     "Feb  3 04:05:06",
+	expectOK: true,
+}, {
+	layout:   time.Kitchen,
+	text:     "11:23PM",
+	expectOK: true,
+}, {
+	layout: time.Kitchen,
+	text:   "13:23PM",
+}, {
+	layout:   "[02/Jan/2006:15:04:05 -0700]",
+	text:     "[10/Oct/2000:13:55:36 -0700]",
+	expectOK: true,
+}, {
+	layout:   "2006-01-02 15:04:05.000",
+	text:     "2020-10-10 01:02:03.456",
+	expectOK: true,
+}, {
+	layout: "2006-01-02 15:04:05.000",
+	text:   "2020-10-10 01:02:03.45",
+}, {
+	layout:   "January 2%o at %ipm",
+	text:     "March 21st at 3:30pm",
+	expectOK: true,
+}, {
+	layout: "January 2%o at %ipm",
+	text:   "March 21st at 3:00:00pm",
+}, {
+	layout:   `\Day 002 of 2006 \.`,
+	text:     "Day 061 of 2024 .",
+	expectOK: true,
+}, {
+	layout: `\Day 002 of 2006 \.`,
+	text:   "Day 061 of 2024 x",
+}}
+
+func TestLayoutPattern(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range layoutPatternTests {
+		c.Run(test.layout+"/"+test.text, func(c *qt.C) {
+			pat := regexp.MustCompile("^(?:" + LayoutPattern(test.layout) + ")$")
+			c.Assert(pat.MatchString(test.text), qt.Equals, test.expectOK, qt.Commentf("pattern %s", pat))
+			if test.expectOK {
+				_, err := Style{}.Parse(test.layout, test.text)
+				c.Assert(err, qt.IsNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package timeformat
+
+import (
+	"fmt"
+	"time"
+)
+
+var persianMonths = []string{
+	"Farvardin", "Ordibehesht", "Khordad", "Tir", "Mordad", "Shahrivar",
+	"Mehr", "Aban", "Azar", "Dey", "Bahman", "Esfand",
+}
+
+// persianBreaks holds the years in which the 33 year leap year
+// cycle of the Persian calendar is broken, as calculated by
+// Kazimierz M. Borkowski ("The Persian calendar for 3000 years",
+// Earth, Moon and Planets 74, 1996).
+var persianBreaks = []int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181,
+	1210, 1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+// persianCalendar implements Calendar for the Persian (Solar Hijri)
+// calendar, using Borkowski's algorithm, which matches the
+// astronomical calendar from 1 to 3177 AP.
+type persianCalendar struct{}
+
+func (persianCalendar) Name() string { return "persian" }
+
+func (persianCalendar) FromGregorian(d *Date) (*Date, error) {
+	n := dayFromGregorian(d.Year, d.Month, d.Day)
+	year := d.Year - 621
+	leap, march, err := persianYear(year)
+	if err != nil {
+		return nil, err
+	}
+	// k holds the number of days since 1 Farvardin.
+	k := n - dayFromGregorian(d.Year, time.March, march)
+	if k < 0 {
+		year--
+		if _, _, err := persianYear(year); err != nil {
+			return nil, err
+		}
+		// The date is in the last months of the previous year.
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	} else if k <= 185 {
+		return withDate(d, "", year, time.Month(1+k/31), k%31+1), nil
+	} else {
+		k -= 186
+	}
+	return withDate(d, "", year, time.Month(7+k/30), k%30+1), nil
+}
+
+func (cal persianCalendar) ToGregorian(d *Date) (*Date, error) {
+	leap, march, err := persianYear(d.Year)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDate(cal, d, 12, func() int {
+		switch {
+		case d.Month <= 6:
+			return 31
+		case d.Month <= 11 || leap == 0:
+			return 30
+		}
+		return 29
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := int(d.Month)
+	n := dayFromGregorian(d.Year+621, time.March, march) + (m-1)*31 - m/7*(m-7) + d.Day - 1
+	year, month, day := gregorianFromDay(n)
+	return withDate(d, "", year, month, day), nil
+}
+
+func (persianCalendar) MonthNames(year int) []string {
+	return persianMonths
+}
+
+func (persianCalendar) Eras() []string {
+	return nil
+}
+
+// persianYear returns information about the given Persian year:
+// the number of years since the last leap year (0 if the year is
+// a leap year) and the day in March of the Gregorian year
+// starting in the year on which 1 Farvardin falls.
+func persianYear(year int) (leap, march int, err error) {
+	breaks := persianBreaks
+	if year < breaks[0] || year >= breaks[len(breaks)-1] {
+		return 0, 0, fmt.Errorf("year %d out of range in persian calendar", year)
+	}
+	gyear := year + 621
+	leapJ := -14
+	jp := breaks[0]
+	jump := 0
+	for _, jm := range breaks[1:] {
+		jump = jm - jp
+		if year < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := year - jp
+	// Find the number of leap years since the start of the
+	// Persian and Gregorian calendars.
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gyear/4 - (gyear/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return leap, march, nil
+}
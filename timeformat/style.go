@@ -0,0 +1,324 @@
+package timeformat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Style holds the conventions used when formatting and
+// parsing times.
+type Style struct {
+	// Locale holds the locale used for month and weekday
+	// names. If it's nil, English is used.
+	Locale *Locale
+
+	// Calendar holds the calendar used for dates.
+	// If it's nil, the Gregorian calendar is used.
+	Calendar Calendar
+
+	// FiscalStart holds the month in which the fiscal
+	// year starts, as used by the %Q, %F, %f and %W
+	// layout elements. If it's zero, January is used.
+	FiscalStart time.Month
+}
+
+func (s Style) locale() *Locale {
+	if s.Locale == nil {
+		return English
+	}
+	return s.Locale
+}
+
+// Format is like time.Time.Format except that it uses the style's locale
+// and calendar. When a calendar is used, the year, month and day in the
+// layout are printed as in that calendar. For calendars with eras, the
+// era is printed before the year: for example "Reiwa 6" for 2006 and "R06"
+// for 06. Calendars with their own months print month names in full
+// for both January and Jan.
+//
+// It returns an error if the time cannot be represented in the calendar.
+//
+// The layout may also contain these elements, which are not
+// understood by the time package:
+//
+//	%Q	the quarter of the fiscal year, from 1 to 4
+//	%F	the fiscal year, named after the year in which it ends
+//	%f	the last two digits of the fiscal year
+//	%W	the week of the fiscal year, as two digits; week 1
+//		starts on the first day of the fiscal year
+//	%V	the ISO 8601 week number, as two digits
+//	%G	the ISO 8601 week-numbering year
+//	%u	the day of the week, from 1 (Monday) to 7 (Sunday)
+//	%w	the day of the week, from 0 (Sunday) to 6 (Saturday)
+//	%j	the day of the year, as three digits
+//	%i	the hour on a 12-hour clock, followed by the
+//		minutes unless they're zero: for example 3 or 3:30
+//	%o	the English ordinal suffix of the day of the
+//		month, for example "rd" for the 3rd
+//	%%	a literal percent sign
+//
+// A backslash makes the character after it literal, so for
+// example the layout `Report \1 of 2006` formats as "Report 1 of 2024".
+func (s Style) Format(t time.Time, layout string) (string, error) {
+	l := s.locale()
+	if s.Calendar == nil && !hasExtensions(layout) {
+		return l.Format(t, layout), nil
+	}
+	var d *Date
+	if s.Calendar != nil {
+		var err error
+		d, err = s.Calendar.FromGregorian(TimeDate(t))
+		if err != nil {
+			return "", err
+		}
+	}
+	var buf strings.Builder
+	for layout != "" {
+		prefix, std, suffix := nextStdChunk(layout)
+		buf.WriteString(prefix)
+		if std == 0 {
+			break
+		}
+		chunk := layout[len(prefix) : len(layout)-len(suffix)]
+		layout = suffix
+		if isExtension(std) {
+			buf.WriteString(s.formatExtension(t, std, chunk))
+			continue
+		}
+		if d == nil {
+			buf.WriteString(l.Format(t, chunk))
+			continue
+		}
+		switch std & stdMask {
+		case stdLongMonth, stdMonth:
+			names, err := s.monthNames(d.Year, std == stdMonth)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(names[d.Month-1])
+		case stdNumMonth:
+			buf.WriteString(strconv.Itoa(int(d.Month)))
+		case stdZeroMonth:
+			fmt.Fprintf(&buf, "%02d", d.Month)
+		case stdDay:
+			buf.WriteString(strconv.Itoa(d.Day))
+		case stdUnderDay:
+			fmt.Fprintf(&buf, "%2d", d.Day)
+		case stdZeroDay:
+			fmt.Fprintf(&buf, "%02d", d.Day)
+		case stdUnderYearDay, stdZeroYearDay:
+			start, err := s.Calendar.ToGregorian(withDate(d, d.Era, d.Year, 1, 1))
+			if err != nil {
+				return "", fmt.Errorf("cannot find start of year: %v", err)
+			}
+			yday := dayFromGregorian(t.Date()) - dayFromGregorian(start.Year, start.Month, start.Day) + 1
+			if std == stdUnderYearDay {
+				fmt.Fprintf(&buf, "%3d", yday)
+			} else {
+				fmt.Fprintf(&buf, "%03d", yday)
+			}
+		case stdLongYear:
+			if d.Era != "" {
+				buf.WriteString(d.Era + " ")
+			}
+			buf.WriteString(strconv.Itoa(d.Year))
+		case stdYear:
+			if d.Era != "" {
+				buf.WriteString(d.Era[:1])
+			}
+			fmt.Fprintf(&buf, "%02d", floorMod(d.Year, 100))
+		default:
+			buf.WriteString(l.Format(t, chunk))
+		}
+	}
+	return buf.String(), nil
+}
+
+// monthNames returns the names of the months in the given year
+// of the style's calendar.
+func (s Style) monthNames(year int, short bool) ([]string, error) {
+	names := s.Calendar.MonthNames(year)
+	if names == nil {
+		return nil, fmt.Errorf("%s calendar has no month names", s.Calendar.Name())
+	}
+	if len(names) == 12 && names[0] == English.Months[0] {
+		// The calendar uses the Gregorian months,
+		// so use the locale's names for them.
+		l := s.locale()
+		if short {
+			return l.ShortMonths[:], nil
+		}
+		return l.Months[:], nil
+	}
+	return names, nil
+}
+
+// monthNameLists returns all the lists of month names
+// that are accepted when parsing the given year of the
+// style's calendar.
+func (s Style) monthNameLists(year int) [][]string {
+	names := s.Calendar.MonthNames(year)
+	if names == nil {
+		return nil
+	}
+	lists := [][]string{names}
+	if len(names) == 12 && names[0] == English.Months[0] {
+		l := s.locale()
+		lists = append(lists, English.ShortMonths[:], l.Months[:], l.ShortMonths[:])
+	}
+	return lists
+}
+
+// allMonthNames returns all the month names accepted when parsing
+// any year in the style's calendar. The names may vary from year to year
+// (for example in leap years in the Hebrew calendar), so look at the names
+// for a full 19 year cycle.
+func (s Style) allMonthNames() [][]string {
+	var all [][]string
+	for year := 1; year <= 19; year++ {
+		all = append(all, s.monthNameLists(year)...)
+	}
+	return all
+}
+
+// Parse is like time.Parse except that it uses the style's
+// locale and calendar and accepts the extra layout elements
+// described in Format. When a calendar is used, the layout must
+// specify a year and either a month and day or a day of the year,
+// and two digit years are only accepted for calendars with eras.
+//
+// If the layout has no month or day, an ISO week, day of the year or
+// fiscal year, quarter or week in the value specifies the date at the
+// start of that period. If there's an ISO week but no ISO year or a
+// fiscal quarter or week but no fiscal year, the year is used instead.
+// Otherwise the extra elements must match the date.
+func (s Style) Parse(layout, value string) (time.Time, error) {
+	return s.ParseInLocation(layout, value, time.UTC)
+}
+
+// ParseInLocation is like time.ParseInLocation except that it uses
+// the style's locale and calendar. See Parse for details.
+func (s Style) ParseInLocation(layout, value string, loc *time.Location) (time.Time, error) {
+	l := s.locale()
+	if s.Calendar == nil {
+		if hasExtensions(layout) {
+			return s.parseExtensions(layout, value, loc)
+		}
+		return l.ParseInLocation(layout, value, loc)
+	}
+	name := s.Calendar.Name()
+	// Take the date elements of the layout out of the layout and value,
+	// leaving the rest to be parsed by the time package.
+	elems, restLayout, restValue, ok := s.splitLayout(layout, value, func(std int) bool {
+		return isExtension(std) || stdComponents[std&stdMask]&(Year|Month|Day) != 0
+	})
+	if !ok {
+		return time.Time{}, fmt.Errorf("cannot parse %q as %q in %s calendar", value, layout, name)
+	}
+	var d Date
+	var monthName string
+	yday := 0
+	var have Components
+	for _, e := range elems {
+		switch e.std & stdMask {
+		case stdLongMonth, stdMonth:
+			monthName = e.text
+			have |= Month
+		case stdNumMonth, stdZeroMonth:
+			n, _ := strconv.Atoi(e.text)
+			d.Month = time.Month(n)
+			have |= Month
+		case stdDay, stdUnderDay, stdZeroDay:
+			d.Day, _ = strconv.Atoi(strings.TrimLeft(e.text, " "))
+			have |= Day
+		case stdUnderYearDay, stdZeroYearDay:
+			yday, _ = strconv.Atoi(strings.TrimLeft(e.text, " "))
+		case stdLongYear, stdYear:
+			i := strings.IndexAny(e.text, "0123456789")
+			d.Era = strings.TrimSpace(e.text[:i])
+			d.Year, _ = strconv.Atoi(e.text[i:])
+			have |= Year
+		case stdEscape, stdPercent:
+		default:
+			return time.Time{}, fmt.Errorf("%%-elements are not supported when parsing in the %s calendar", name)
+		}
+	}
+	if have&Year == 0 || have&(Month|Day) != Month|Day && yday == 0 {
+		return time.Time{}, fmt.Errorf("layout %q does not specify a full date in the %s calendar", layout, name)
+	}
+	if monthName != "" {
+		d.Month = 0
+		for _, names := range s.monthNameLists(d.Year) {
+			for i, name := range names {
+				if strings.EqualFold(strings.TrimSuffix(name, "."), strings.TrimSuffix(monthName, ".")) {
+					d.Month = time.Month(i + 1)
+				}
+			}
+		}
+		if d.Month == 0 {
+			return time.Time{}, fmt.Errorf("month %q not found in year %d of %s calendar", monthName, d.Year, name)
+		}
+	}
+	if have&(Month|Day) != Month|Day {
+		d.Month, d.Day = 1, 1
+	}
+	gd, err := s.Calendar.ToGregorian(&d)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if have&(Month|Day) != Month|Day {
+		gd.Year, gd.Month, gd.Day = gregorianFromDay(dayFromGregorian(gd.Year, gd.Month, gd.Day) + yday - 1)
+		if d1, err := s.Calendar.FromGregorian(gd); err != nil || d1.Year != d.Year {
+			return time.Time{}, fmt.Errorf("day of year %d out of range in %s calendar", yday, name)
+		}
+	}
+	t, err := l.ParseInLocation(restLayout, restValue, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(gd.Year, gd.Month, gd.Day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), nil
+}
+
+// stdPattern returns the pattern for the given std value,
+// which is not stdFracSecond0.
+func (s Style) stdPattern(std int) string {
+	l := s.locale()
+	if s.Calendar == nil {
+		return l.stdPattern(std)
+	}
+	var eras string
+	if names := s.Calendar.Eras(); names != nil {
+		eras = namesPattern(names)
+	}
+	switch std & stdMask {
+	case stdLongMonth, stdMonth:
+		return namesPattern(s.allMonthNames()...)
+	case stdNumMonth:
+		return `[0-9]{1,2}`
+	case stdZeroMonth, stdZeroDay:
+		return `[0-9]{2}`
+	case stdDay:
+		return `[0-9]{1,2}`
+	case stdUnderDay:
+		return ` ?[0-9]{1,2}`
+	case stdLongYear:
+		if eras != "" {
+			return eras + ` *[0-9]{1,4}`
+		}
+		return `[0-9]{1,5}`
+	case stdYear:
+		if eras != "" {
+			// The era's initial followed by two digits.
+			initials := make([]string, len(s.Calendar.Eras()))
+			for i, era := range s.Calendar.Eras() {
+				initials[i] = era[:1]
+			}
+			return namesPattern(initials) + `[0-9]{2}`
+		}
+		// Two digit years are ambiguous in other calendars.
+		return `$.^`
+	}
+	return l.stdPattern(std)
+}
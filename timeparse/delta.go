@@ -0,0 +1,237 @@
+package timeparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delta represents a change to a time. The date components are
+// applied first, as by time.Time.AddDate, followed by the duration.
+type Delta struct {
+	Years    int
+	Months   int
+	Days     int
+	Duration time.Duration
+}
+
+// ParseDelta parses a delta. As well as any duration accepted by
+// time.ParseDuration, it accepts the units y (year, years),
+// q (quarter, quarters), mo (month, months), w (week, weeks) and
+// d (day, days), which may be mixed with the time.ParseDuration units,
+// for example "+1y2mo", "-1d12h" or "3w". A sign applies to all the
+// following components up to the next sign, so "+1d-1h" is one hour
+// short of a day later.
+func ParseDelta(s string) (Delta, error) {
+	if dur, err := time.ParseDuration(s); err == nil {
+		return Delta{Duration: dur}, nil
+	}
+	orig := s
+	var d Delta
+	neg := false
+	for {
+		if s != "" && (s[0] == '-' || s[0] == '+') {
+			neg = s[0] == '-'
+			s = s[1:]
+		}
+		i := 0
+		for ; i < len(s) && isNumberByte(s[i]); i++ {
+		}
+		if i == 0 {
+			return Delta{}, fmt.Errorf("invalid duration %q", orig)
+		}
+		num := s[:i]
+		s = s[i:]
+		i = 0
+		for ; i < len(s) && !isNumberByte(s[i]) && s[i] != '-' && s[i] != '+'; i++ {
+		}
+		if i == 0 {
+			return Delta{}, fmt.Errorf("missing unit in duration %q", orig)
+		}
+		unit := s[:i]
+		s = s[i:]
+		if err := d.addComponent(num, unit, neg); err != nil {
+			return Delta{}, fmt.Errorf("%v in duration %q", err, orig)
+		}
+		if s == "" {
+			return d, nil
+		}
+	}
+}
+
+// dateUnits holds the delta for one of each date unit.
+var dateUnits = map[string]Delta{
+	"y":        {Years: 1},
+	"year":     {Years: 1},
+	"years":    {Years: 1},
+	"q":        {Months: 3},
+	"quarter":  {Months: 3},
+	"quarters": {Months: 3},
+	"mo":       {Months: 1},
+	"month":    {Months: 1},
+	"months":   {Months: 1},
+	"w":        {Days: 7},
+	"week":     {Days: 7},
+	"weeks":    {Days: 7},
+	"d":        {Days: 1},
+	"day":      {Days: 1},
+	"days":     {Days: 1},
+}
+
+// addComponent adds the component with the given number and
+// unit to d, negated if neg is true.
+func (d *Delta) addComponent(num, unit string, neg bool) error {
+	u, ok := dateUnits[unit]
+	if !ok {
+		dur, err := time.ParseDuration(num + unit)
+		if err != nil {
+			return fmt.Errorf("unknown unit %q", unit)
+		}
+		if neg {
+			dur = -dur
+		}
+		d.Duration += dur
+		return nil
+	}
+	n, err := strconv.ParseInt(num, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", num)
+	}
+	v := int(n)
+	if neg {
+		v = -v
+	}
+	d.Years += u.Years * v
+	d.Months += u.Months * v
+	d.Days += u.Days * v
+	return nil
+}
+
+func isNumberByte(c byte) bool {
+	return c == '.' || '0' <= c && c <= '9'
+}
+
+// Add returns t with the delta added.
+func (d Delta) Add(t time.Time) time.Time {
+	if d.Years != 0 || d.Months != 0 || d.Days != 0 {
+		t = t.AddDate(d.Years, d.Months, d.Days)
+	}
+	return t.Add(d.Duration)
+}
+
+// String returns the delta in the form accepted by ParseDelta,
+// for example "+1y2mo" or "-1h30m0s". It always starts with a sign.
+func (d Delta) String() string {
+	if d == (Delta{}) {
+		return "+0s"
+	}
+	var buf strings.Builder
+	neg := false
+	write := func(v int64, unit string) {
+		if v == 0 {
+			return
+		}
+		if buf.Len() == 0 || (v < 0) != neg {
+			neg = v < 0
+			if neg {
+				buf.WriteByte('-')
+			} else {
+				buf.WriteByte('+')
+			}
+		}
+		if v < 0 {
+			v = -v
+		}
+		if unit == "" {
+			buf.WriteString(time.Duration(v).String())
+		} else {
+			buf.WriteString(strconv.FormatInt(v, 10))
+			buf.WriteString(unit)
+		}
+	}
+	write(int64(d.Years), "y")
+	write(int64(d.Months), "mo")
+	write(int64(d.Days), "d")
+	write(int64(d.Duration), "")
+	return buf.String()
+}
+
+// Diff returns the delta from t0 to t1, in whole years, months and days
+// followed by the remaining duration, so that Diff(t0, t1).Add(t0)
+// is t1. Days are counted in the location of t0. When t1 is before t0,
+// all the components are negative or zero.
+func Diff(t0, t1 time.Time) Delta {
+	sign := 1
+	if t1.Before(t0) {
+		sign = -1
+	}
+	// passed reports whether t is beyond t1 in the direction of the delta.
+	passed := func(t time.Time) bool {
+		if sign > 0 {
+			return t.After(t1)
+		}
+		return t.Before(t1)
+	}
+	y0, m0, _ := t0.Date()
+	y1, m1, _ := t1.In(t0.Location()).Date()
+	months := (y1-y0)*12 + int(m1-m0)
+	for months != 0 && passed(t0.AddDate(0, months, 0)) {
+		months -= sign
+	}
+	days := int(t1.Sub(t0.AddDate(0, months, 0)) / (24 * time.Hour))
+	for days != 0 && passed(t0.AddDate(0, months, days)) {
+		days -= sign
+	}
+	for !passed(t0.AddDate(0, months, days+sign)) {
+		days += sign
+	}
+	return Delta{
+		Years:    months / 12,
+		Months:   months % 12,
+		Days:     days,
+		Duration: t1.Sub(t0.AddDate(0, months, days)),
+	}
+}
+
+// Truncate returns t truncated to the start of the given unit, which is
+// a calendar unit (y or year, fy or fiscalyear, q or quarter, mo or month,
+// w or week, d or day) or a duration such as 15m or h, which is measured
+// from the start of the day. Weeks start on Monday. Quarters and fiscal
+// years start at the beginning of the fiscal year, which starts at
+// the beginning of the given month (zero means January).
+func Truncate(t time.Time, unit string, fiscalStart time.Month) (time.Time, error) {
+	if fiscalStart == 0 {
+		fiscalStart = time.January
+	}
+	year, month, day := t.Date()
+	// monthsIn holds the number of months since the
+	// start of the fiscal year.
+	monthsIn := time.Month((int(month) - int(fiscalStart) + 12) % 12)
+	switch unit {
+	case "y", "year":
+		month, day = time.January, 1
+	case "fy", "fiscalyear":
+		month, day = month-monthsIn, 1
+	case "q", "quarter":
+		month, day = month-monthsIn%3, 1
+	case "mo", "month":
+		day = 1
+	case "w", "week":
+		day -= (int(t.Weekday()) + 6) % 7
+	case "d", "day":
+	default:
+		dunit := unit
+		if dunit != "" && (dunit[0] < '0' || dunit[0] > '9') {
+			// Allow h as well as 1h.
+			dunit = "1" + dunit
+		}
+		d, err := time.ParseDuration(dunit)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid truncation unit %q", unit)
+		}
+		midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+		return midnight.Add(t.Sub(midnight).Truncate(d)), nil
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location()), nil
+}
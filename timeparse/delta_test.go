@@ -0,0 +1,204 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var parseDeltaTests = []struct {
+	s      string
+	expect Delta
+	str    string
+}{{
+	s:      "+1h30m",
+	expect: Delta{Duration: 90 * time.Minute},
+	str:    "+1h30m0s",
+}, {
+	s:      "-1.5s",
+	expect: Delta{Duration: -1500 * time.Millisecond},
+	str:    "-1.5s",
+}, {
+	s:      "+1y2mo3d",
+	expect: Delta{Years: 1, Months: 2, Days: 3},
+	str:    "+1y2mo3d",
+}, {
+	s:      "+1month3days",
+	expect: Delta{Months: 1, Days: 3},
+	str:    "+1mo3d",
+}, {
+	s:      "-2q1w",
+	expect: Delta{Months: -6, Days: -7},
+	str:    "-6mo7d",
+}, {
+	s:      "+1d12h",
+	expect: Delta{Days: 1, Duration: 12 * time.Hour},
+	str:    "+1d12h0m0s",
+}, {
+	s:      "+1d-1h",
+	expect: Delta{Days: 1, Duration: -time.Hour},
+	str:    "+1d-1h0m0s",
+}, {
+	s:      "-1y+6mo",
+	expect: Delta{Years: -1, Months: 6},
+	str:    "-1y+6mo",
+}, {
+	s:      "-1w2d3h",
+	expect: Delta{Days: -9, Duration: -3 * time.Hour},
+	str:    "-9d3h0m0s",
+}, {
+	s:      "+1d1.5h",
+	expect: Delta{Days: 1, Duration: 90 * time.Minute},
+	str:    "+1d1h30m0s",
+}, {
+	s:      "+0d",
+	expect: Delta{},
+	str:    "+0s",
+}}
+
+func TestParseDelta(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseDeltaTests {
+		c.Run(test.s, func(c *qt.C) {
+			d, err := ParseDelta(test.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(d, qt.Equals, test.expect)
+			c.Assert(d.String(), qt.Equals, test.str)
+			d, err = ParseDelta(d.String())
+			c.Assert(err, qt.IsNil)
+			c.Assert(d, qt.Equals, test.expect)
+		})
+	}
+}
+
+var parseDeltaErrorTests = []struct {
+	s      string
+	expect string
+}{{
+	s:      "",
+	expect: `invalid duration ""`,
+}, {
+	s:      "+",
+	expect: `invalid duration "\+"`,
+}, {
+	s:      "+1",
+	expect: `missing unit in duration "\+1"`,
+}, {
+	s:      "+1x",
+	expect: `unknown unit "x" in duration "\+1x"`,
+}, {
+	s:      "+1.5d",
+	expect: `invalid number "1.5" in duration "\+1.5d"`,
+}, {
+	s:      "+1dd",
+	expect: `unknown unit "dd" in duration "\+1dd"`,
+}, {
+	s:      "+1d-",
+	expect: `invalid duration "\+1d-"`,
+}, {
+	s:      "+1d2x",
+	expect: `unknown unit "x" in duration "\+1d2x"`,
+}}
+
+func TestParseDeltaError(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseDeltaErrorTests {
+		_, err := ParseDelta(test.s)
+		c.Check(err, qt.ErrorMatches, test.expect, qt.Commentf("%q", test.s))
+	}
+}
+
+func TestDeltaAdd(t *testing.T) {
+	c := qt.New(t)
+	t0 := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	d := Delta{Months: 1, Days: 1, Duration: -time.Hour}
+	c.Assert(d.Add(t0), qt.Equals, time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC))
+}
+
+var truncateTests = []struct {
+	unit   string
+	start  time.Month
+	expect time.Time
+}{{
+	unit:   "y",
+	expect: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "fiscalyear",
+	start:  time.October,
+	expect: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "q",
+	expect: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "quarter",
+	start:  time.February,
+	expect: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "mo",
+	expect: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "week",
+	expect: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "d",
+	expect: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+}, {
+	unit:   "h",
+	expect: time.Date(2024, 5, 15, 13, 0, 0, 0, time.UTC),
+}, {
+	unit:   "15m",
+	expect: time.Date(2024, 5, 15, 13, 45, 0, 0, time.UTC),
+}}
+
+func TestTruncate(t *testing.T) {
+	c := qt.New(t)
+	// A Wednesday.
+	t0 := time.Date(2024, 5, 15, 13, 47, 12, 0, time.UTC)
+	for _, test := range truncateTests {
+		got, err := Truncate(t0, test.unit, test.start)
+		c.Assert(err, qt.IsNil)
+		c.Check(got, qt.Equals, test.expect, qt.Commentf("%s", test.unit))
+	}
+	_, err := Truncate(t0, "fortnight", 0)
+	c.Assert(err, qt.ErrorMatches, `invalid truncation unit "fortnight"`)
+}
+
+var diffTests = []struct {
+	t0, t1 time.Time
+	expect Delta
+}{{
+	t0:     time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+	t1:     time.Date(2025, 3, 2, 12, 30, 0, 0, time.UTC),
+	expect: Delta{Years: 1, Days: 30, Duration: 150 * time.Minute},
+}, {
+	t0:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	t1:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	expect: Delta{},
+}, {
+	t0:     time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+	t1:     time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC),
+	expect: Delta{Duration: -time.Hour},
+}, {
+	t0:     time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+	t1:     time.Date(2024, 1, 10, 18, 0, 0, 0, time.UTC),
+	expect: Delta{Months: -2, Days: -4, Duration: -18 * time.Hour},
+}, {
+	t0:     time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC),
+	t1:     time.Date(2024, 4, 1, 11, 0, 0, 0, time.UTC),
+	expect: Delta{Days: 1, Duration: 23 * time.Hour},
+}}
+
+func TestDiff(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range diffTests {
+		d := Diff(test.t0, test.t1)
+		c.Check(d, qt.Equals, test.expect, qt.Commentf("%v %v", test.t0, test.t1))
+		c.Check(d.Add(test.t0), qt.Equals, test.t1)
+		// The delta's string form, which mixes date and time
+		// units, parses back to the same delta.
+		d1, err := ParseDelta(d.String())
+		c.Assert(err, qt.IsNil)
+		c.Check(d1, qt.Equals, d)
+	}
+}
@@ -0,0 +1,329 @@
+package timeparse
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// epochFormat describes a time format that counts units
+// since some epoch.
+type epochFormat struct {
+	// epoch holds the time of the zero value.
+	epoch time.Time
+	// unit holds the length of one unit in seconds.
+	unit *big.Rat
+	// gps is true when the count includes leap seconds,
+	// as GPS time does.
+	gps bool
+	// fixed is true when the count is a 64-bit NTP timestamp,
+	// conventionally printed as two 32-bit hex numbers.
+	fixed bool
+	// decimal is true when the count is printed as a decimal
+	// number rather than as a whole number of units.
+	decimal bool
+	// places holds the number of decimal places printed
+	// when decimal is true. If it's negative, as many places as needed
+	// are printed, up to nanosecond precision.
+	places int
+}
+
+var (
+	unixEpoch   = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ntpEpoch    = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ntfsEpoch   = time.Date(1601, time.January, 1, 0, 0, 0, 0, time.UTC)
+	dotnetEpoch = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cocoaEpoch  = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	gpsEpoch    = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
+)
+
+// epochFormats holds all the named epoch formats.
+var epochFormats = map[string]*epochFormat{
+	"unix": {
+		epoch: unixEpoch,
+		unit:  big.NewRat(1, 1),
+	},
+	"unixmilli": {
+		epoch: unixEpoch,
+		unit:  big.NewRat(1, 1e3),
+	},
+	"unixmicro": {
+		epoch: unixEpoch,
+		unit:  big.NewRat(1, 1e6),
+	},
+	"unixnano": {
+		epoch: unixEpoch,
+		unit:  big.NewRat(1, 1e9),
+	},
+	"unixfloat": {
+		epoch:   unixEpoch,
+		unit:    big.NewRat(1, 1),
+		decimal: true,
+		places:  -1,
+	},
+	"ntp": {
+		epoch: ntpEpoch,
+		unit:  big.NewRat(1, 1),
+	},
+	"ntp64": {
+		epoch: ntpEpoch,
+		unit:  big.NewRat(1, 1<<32),
+		fixed: true,
+	},
+	"filetime": {
+		epoch: ntfsEpoch,
+		unit:  big.NewRat(1, 1e7),
+	},
+	"dotnet": {
+		epoch: dotnetEpoch,
+		unit:  big.NewRat(1, 1e7),
+	},
+	"cocoa": {
+		epoch: cocoaEpoch,
+		unit:  big.NewRat(1, 1),
+	},
+	"webkit": {
+		epoch: ntfsEpoch,
+		unit:  big.NewRat(1, 1e6),
+	},
+	"gps": {
+		epoch: gpsEpoch,
+		unit:  big.NewRat(1, 1),
+		gps:   true,
+	},
+}
+
+// leapSeconds holds the times (in UTC) just after each leap second
+// that has been inserted since the GPS epoch.
+var leapSeconds = []time.Time{
+	time.Date(1981, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1982, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1983, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1985, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1988, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1991, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1992, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1993, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1994, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1996, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1997, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2009, time.January, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2012, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var epochLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	time.RFC3339Nano,
+}
+
+// lookupEpochFormat returns the epoch format with the given
+// name, which may be a custom epoch specification of the form
+// "epoch:date[/unit]". It returns nil if the name isn't an epoch format.
+func lookupEpochFormat(name string) (*epochFormat, error) {
+	if f := epochFormats[name]; f != nil {
+		return f, nil
+	}
+	if strings.HasPrefix(name, "unixfloat:") {
+		places, err := strconv.Atoi(strings.TrimPrefix(name, "unixfloat:"))
+		if err != nil || places < 0 || places > 9 {
+			return nil, fmt.Errorf("invalid decimal places in %q", name)
+		}
+		f := *epochFormats["unixfloat"]
+		f.places = places
+		return &f, nil
+	}
+	if !strings.HasPrefix(name, "epoch:") {
+		return nil, nil
+	}
+	spec := strings.TrimPrefix(name, "epoch:")
+	unit := "s"
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		spec, unit = spec[:i], spec[i+1:]
+	}
+	var epoch time.Time
+	var err error
+	for _, layout := range epochLayouts {
+		epoch, err = time.Parse(layout, spec)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid epoch %q in %q", spec, name)
+	}
+	if unit == "" || unit[0] < '0' || unit[0] > '9' {
+		unit = "1" + unit
+	}
+	d, err := time.ParseDuration(unit)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid unit %q in %q", unit, name)
+	}
+	return &epochFormat{
+		epoch: epoch,
+		unit:  big.NewRat(int64(d), 1e9),
+	}, nil
+}
+
+// numberPattern matches a decimal number, possibly with a fractional
+// part and an exponent. The exponent is limited to avoid silly
+// amounts of work on very large numbers.
+var numberPattern = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]{1,3})?$`)
+
+// parse parses s as a count of units since the epoch.
+// The count may have a fractional part and an exponent;
+// it's converted exactly, rounding down to the nearest nanosecond.
+func (f *epochFormat) parse(s string) (time.Time, error) {
+	v, err := f.parseValue(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return f.time(v)
+}
+
+func (f *epochFormat) parseValue(s string) (*big.Rat, error) {
+	if f.fixed {
+		v := new(big.Int)
+		if i := strings.Index(s, "."); i >= 0 {
+			fracHex := s[i+1:]
+			secs, ok1 := new(big.Int).SetString(s[:i], 16)
+			frac, ok2 := new(big.Int).SetString(fracHex, 16)
+			if !ok1 || !ok2 || secs.Sign() < 0 || frac.Sign() < 0 || len(fracHex) > 8 {
+				return nil, fmt.Errorf("invalid NTP timestamp %q", s)
+			}
+			secs.Lsh(secs, 32)
+			frac.Lsh(frac, uint(32-4*len(fracHex)))
+			return new(big.Rat).SetInt(v.Add(secs, frac)), nil
+		}
+		if strings.HasPrefix(s, "0x") {
+			if _, ok := v.SetString(s[2:], 16); !ok {
+				return nil, fmt.Errorf("invalid NTP timestamp %q", s)
+			}
+			return new(big.Rat).SetInt(v), nil
+		}
+	}
+	if !numberPattern.MatchString(s) {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
+
+// time returns the time that is v units after the epoch.
+func (f *epochFormat) time(v *big.Rat) (time.Time, error) {
+	secs := new(big.Rat).Mul(v, f.unit)
+	// Note: Euclidean division rounds towards negative infinity
+	// when the divisor is positive, as denominators always are.
+	ns := new(big.Int).Mul(secs.Num(), big.NewInt(1e9))
+	ns.DivMod(ns, secs.Denom(), new(big.Int))
+	sec, nsec := new(big.Int).DivMod(ns, big.NewInt(1e9), new(big.Int))
+	sec.Add(sec, big.NewInt(f.epoch.Unix()))
+	if !sec.IsInt64() || sec.Int64() > maxUnix || sec.Int64() < minUnix {
+		return time.Time{}, fmt.Errorf("time out of range")
+	}
+	t := time.Unix(sec.Int64(), nsec.Int64())
+	if f.gps {
+		t = gpsToUTC(t)
+	}
+	return t, nil
+}
+
+// The range of Unix times in seconds that can be represented
+// by a time.Time and still printed with a four digit year.
+const (
+	minUnix = -62135596800 // 0001-01-01
+	maxUnix = 253402300799 // 9999-12-31T23:59:59
+)
+
+// value returns the number of units between the epoch and t.
+func (f *epochFormat) value(t time.Time) *big.Rat {
+	if f.gps {
+		t = utcToGPS(t)
+	}
+	secs := big.NewRat(t.Unix()-f.epoch.Unix(), 1)
+	secs.Add(secs, big.NewRat(int64(t.Nanosecond()), 1e9))
+	return secs.Quo(secs, f.unit)
+}
+
+// format returns t as a count of units since the epoch,
+// rounded down to the number of decimal places
+// specified by the format.
+func (f *epochFormat) format(t time.Time) string {
+	v := f.value(t)
+	if f.decimal {
+		return formatDecimal(v, f.places)
+	}
+	n, _ := new(big.Int).DivMod(v.Num(), v.Denom(), new(big.Int))
+	if f.fixed && n.Sign() >= 0 {
+		secs := new(big.Int).Rsh(n, 32)
+		frac := new(big.Int).And(n, big.NewInt(1<<32-1))
+		return fmt.Sprintf("%08x.%08x", secs, frac)
+	}
+	return n.String()
+}
+
+// formatDecimal returns v as a decimal number rounded down to the
+// given number of decimal places. If places is negative,
+// up to 9 places are used, omitting trailing zeros.
+func formatDecimal(v *big.Rat, places int) string {
+	trim := places < 0
+	if trim {
+		places = 9
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	n := new(big.Int).Mul(v.Num(), scale)
+	n.DivMod(n, v.Denom(), new(big.Int))
+	neg := n.Sign() < 0
+	digits := new(big.Int).Abs(n).String()
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-places], digits[len(digits)-places:]
+	if trim {
+		frac = strings.TrimRight(frac, "0")
+	}
+	s := whole
+	if frac != "" {
+		s += "." + frac
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// gpsToUTC converts from a time that counts leap seconds since
+// the GPS epoch to UTC.
+func gpsToUTC(t time.Time) time.Time {
+	n := 0
+	for i, leap := range leapSeconds {
+		if t.Add(-time.Duration(i+1) * time.Second).Before(leap) {
+			break
+		}
+		n = i + 1
+	}
+	return t.Add(-time.Duration(n) * time.Second)
+}
+
+// utcToGPS is the inverse of gpsToUTC.
+func utcToGPS(t time.Time) time.Time {
+	n := 0
+	for _, leap := range leapSeconds {
+		if t.Before(leap) {
+			break
+		}
+		n++
+	}
+	return t.Add(time.Duration(n) * time.Second)
+}
@@ -0,0 +1,440 @@
+package timeparse
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var epochTests = []struct {
+	testName string
+	format   string
+	s        string
+	expect   time.Time
+	// expectFormat holds the formatted time,
+	// if it's different from s.
+	expectFormat string
+}{{
+	testName: "unix",
+	format:   "unix",
+	s:        "1700000000",
+	expect:   time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+}, {
+	testName: "unix-negative",
+	format:   "unix",
+	s:        "-1",
+	expect:   time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+}, {
+	testName: "unixmilli-negative",
+	format:   "unixmilli",
+	s:        "-1500",
+	expect:   time.Date(1969, 12, 31, 23, 59, 58, 5e8, time.UTC),
+}, {
+	testName: "ntp",
+	format:   "ntp",
+	s:        "2208988800",
+	expect:   unixEpoch,
+}, {
+	testName: "ntp-epoch",
+	format:   "ntp",
+	s:        "0",
+	expect:   time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "ntp64",
+	format:   "ntp64",
+	s:        "83aa7e80.80000000",
+	expect:   time.Date(1970, 1, 1, 0, 0, 0, 5e8, time.UTC),
+}, {
+	testName:     "ntp64-short-fraction",
+	format:       "ntp64",
+	s:            "83aa7e80.8",
+	expect:       time.Date(1970, 1, 1, 0, 0, 0, 5e8, time.UTC),
+	expectFormat: "83aa7e80.80000000",
+}, {
+	testName:     "ntp64-hex",
+	format:       "ntp64",
+	s:            "0x83aa7e8040000000",
+	expect:       time.Date(1970, 1, 1, 0, 0, 0, 25e7, time.UTC),
+	expectFormat: "83aa7e80.40000000",
+}, {
+	// NTP fractions are finer than nanoseconds, so the time is
+	// rounded down and prints as a slightly smaller fraction.
+	testName:     "ntp64-fraction-rounding",
+	format:       "ntp64",
+	s:            "83aa7e80.ffffffff",
+	expect:       time.Date(1970, 1, 1, 0, 0, 0, 999999999, time.UTC),
+	expectFormat: "83aa7e80.fffffffb",
+}, {
+	testName: "filetime",
+	format:   "filetime",
+	s:        "116444736000000000",
+	expect:   unixEpoch,
+}, {
+	testName: "filetime-100ns",
+	format:   "filetime",
+	s:        "133444736000000001",
+	expect:   time.Date(2023, 11, 14, 22, 13, 20, 100, time.UTC),
+}, {
+	testName: "dotnet",
+	format:   "dotnet",
+	s:        "621355968000000000",
+	expect:   unixEpoch,
+}, {
+	testName: "dotnet-zero",
+	format:   "dotnet",
+	s:        "0",
+	expect:   time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "cocoa",
+	format:   "cocoa",
+	s:        "0",
+	expect:   time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "cocoa-negative",
+	format:   "cocoa",
+	s:        "-978307200",
+	expect:   unixEpoch,
+}, {
+	testName: "webkit",
+	format:   "webkit",
+	s:        "11644473600000000",
+	expect:   unixEpoch,
+}, {
+	testName: "gps-epoch",
+	format:   "gps",
+	s:        "0",
+	expect:   time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "gps-before-first-leap-second",
+	format:   "gps",
+	s:        "46828799",
+	expect:   time.Date(1981, 6, 30, 23, 59, 59, 0, time.UTC),
+}, {
+	testName: "gps-after-first-leap-second",
+	format:   "gps",
+	s:        "46828801",
+	expect:   time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "gps-before-last-leap-second",
+	format:   "gps",
+	s:        "1167264016",
+	expect:   time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC),
+}, {
+	// 2016-12-31T23:59:60 can't be represented in UTC,
+	// so it's read as the following second.
+	testName:     "gps-last-leap-second",
+	format:       "gps",
+	s:            "1167264017",
+	expect:       time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "1167264018",
+}, {
+	testName: "gps-after-last-leap-second",
+	format:   "gps",
+	s:        "1167264018",
+	expect:   time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "custom-epoch-ms",
+	format:   "epoch:2001-01-01/ms",
+	s:        "1500",
+	expect:   time.Date(2001, 1, 1, 0, 0, 1, 5e8, time.UTC),
+}, {
+	testName: "custom-epoch-rfc3339",
+	format:   "epoch:2024-01-01T12:00:00+02:00/100ns",
+	s:        "-10",
+	expect:   time.Date(2024, 1, 1, 9, 59, 59, 999999000, time.UTC),
+}, {
+	testName: "custom-epoch-hours",
+	format:   "epoch:2024-01-01T00:00:00/h",
+	s:        "25",
+	expect:   time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC),
+}, {
+	testName: "custom-epoch-whole-units",
+	format:   "epoch:2024-01-01/2s",
+	s:        "1.5",
+	expect:   time.Date(2024, 1, 1, 0, 0, 3, 0, time.UTC),
+	// Whole formats print the number of units rounded down.
+	expectFormat: "1",
+}, {
+	testName: "unixfloat",
+	format:   "unixfloat",
+	s:        "1700000000.25",
+	expect:   time.Date(2023, 11, 14, 22, 13, 20, 25e7, time.UTC),
+}, {
+	testName: "unixfloat-whole",
+	format:   "unixfloat",
+	s:        "1700000000",
+	expect:   time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+}, {
+	testName: "unixfloat-negative",
+	format:   "unixfloat",
+	s:        "-0.25",
+	expect:   time.Date(1969, 12, 31, 23, 59, 59, 75e7, time.UTC),
+}, {
+	testName:     "unixfloat-places",
+	format:       "unixfloat:3",
+	s:            "1700000000.25",
+	expect:       time.Date(2023, 11, 14, 22, 13, 20, 25e7, time.UTC),
+	expectFormat: "1700000000.250",
+}, {
+	testName:     "unixfloat-places-round-down",
+	format:       "unixfloat:2",
+	s:            "-0.001",
+	expect:       time.Date(1969, 12, 31, 23, 59, 59, 999e6, time.UTC),
+	expectFormat: "-0.01",
+}, {
+	testName:     "unixfloat-zero-places",
+	format:       "unixfloat:0",
+	s:            "1.999999999",
+	expect:       time.Date(1970, 1, 1, 0, 0, 1, 999999999, time.UTC),
+	expectFormat: "1",
+}}
+
+func TestEpochFormats(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range epochTests {
+		c.Run(test.testName, func(c *qt.C) {
+			f, err := lookupEpochFormat(test.format)
+			c.Assert(err, qt.IsNil)
+			c.Assert(f, qt.Not(qt.IsNil))
+			got, err := f.parse(test.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got.Equal(test.expect), qt.IsTrue, qt.Commentf("got %v", got.UTC()))
+			expectFormat := test.expectFormat
+			if expectFormat == "" {
+				expectFormat = test.s
+			}
+			c.Assert(f.format(got), qt.Equals, expectFormat)
+		})
+	}
+}
+
+func TestEpochFormatErrors(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range []struct {
+		format      string
+		s           string
+		expectError string
+	}{{
+		format:      "unix",
+		s:           "12abc",
+		expectError: `invalid number "12abc"`,
+	}, {
+		format:      "unix",
+		s:           "1e999999",
+		expectError: `invalid number "1e999999"`,
+	}, {
+		format:      "unix",
+		s:           "1e12",
+		expectError: `time out of range`,
+	}, {
+		format:      "ntp64",
+		s:           "83aa7e80.123456789",
+		expectError: `invalid NTP timestamp "83aa7e80.123456789"`,
+	}, {
+		format:      "ntp64",
+		s:           "0xzz",
+		expectError: `invalid NTP timestamp "0xzz"`,
+	}} {
+		f, err := lookupEpochFormat(test.format)
+		c.Assert(err, qt.IsNil)
+		_, err = f.parse(test.s)
+		c.Check(err, qt.ErrorMatches, test.expectError, qt.Commentf("%s %s", test.format, test.s))
+	}
+}
+
+func TestLookupEpochFormatErrors(t *testing.T) {
+	c := qt.New(t)
+	for name, expectError := range map[string]string{
+		"epoch:yesterday":     `invalid epoch "yesterday" in "epoch:yesterday"`,
+		"epoch:2001-01-01/xx": `invalid unit "1xx" in "epoch:2001-01-01/xx"`,
+		"epoch:2001-01-01/0s": `invalid unit "0s" in "epoch:2001-01-01/0s"`,
+		"unixfloat:10":        `invalid decimal places in "unixfloat:10"`,
+	} {
+		_, err := lookupEpochFormat(name)
+		c.Check(err, qt.ErrorMatches, expectError, qt.Commentf("%s", name))
+	}
+	f, err := lookupEpochFormat("rfc3339")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.IsNil)
+}
+
+var formatDecimalTests = []struct {
+	v      *big.Rat
+	places int
+	expect string
+}{{
+	v:      big.NewRat(5, 2),
+	places: -1,
+	expect: "2.5",
+}, {
+	v:      big.NewRat(2, 1),
+	places: -1,
+	expect: "2",
+}, {
+	v:      big.NewRat(1, 1e9),
+	places: -1,
+	expect: "0.000000001",
+}, {
+	// Beyond nanoseconds is dropped.
+	v:      big.NewRat(1, 1e10),
+	places: -1,
+	expect: "0",
+}, {
+	v:      big.NewRat(1, 3),
+	places: 3,
+	expect: "0.333",
+}, {
+	// Rounding is always down, towards negative infinity.
+	v:      big.NewRat(-1, 3),
+	places: 3,
+	expect: "-0.334",
+}, {
+	v:      big.NewRat(-1, 2),
+	places: 0,
+	expect: "-1",
+}, {
+	v:      big.NewRat(0, 1),
+	places: 2,
+	expect: "0.00",
+}, {
+	v:      big.NewRat(123456789, 1e4),
+	places: 2,
+	expect: "12345.67",
+}}
+
+func TestFormatDecimal(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range formatDecimalTests {
+		c.Check(formatDecimal(test.v, test.places), qt.Equals, test.expect, qt.Commentf("%v %d", test.v, test.places))
+	}
+}
+
+func TestGPSLeapSecondsRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	// Every second around each leap second converts back to itself.
+	for _, leap := range leapSeconds {
+		for d := -2 * time.Second; d <= 2*time.Second; d += 500 * time.Millisecond {
+			t0 := leap.Add(d)
+			c.Assert(gpsToUTC(utcToGPS(t0)), qt.DeepEquals, t0, qt.Commentf("%v", t0))
+		}
+	}
+	c.Assert(utcToGPS(gpsEpoch), qt.DeepEquals, gpsEpoch)
+	c.Assert(utcToGPS(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Sub(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), qt.Equals, 18*time.Second)
+}
+
+var parseDecimalTests = []struct {
+	format string
+	s      string
+	expect time.Time
+}{{
+	format: "unix",
+	s:      "1612345678.123456789",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	// Digits beyond nanoseconds are rounded down.
+	format: "unix",
+	s:      "1612345678.1234567899",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	format: "unix",
+	s:      "1612345678.9999999999",
+	expect: time.Unix(1612345678, 999999999),
+}, {
+	// Rounding down is towards negative infinity.
+	format: "unix",
+	s:      "-0.0000000001",
+	expect: time.Unix(-1, 999999999),
+}, {
+	format: "unix",
+	s:      "-1.5",
+	expect: time.Unix(-2, 5e8),
+}, {
+	format: "unix",
+	s:      "1e-9",
+	expect: time.Unix(0, 1),
+}, {
+	format: "unix",
+	s:      "1e-10",
+	expect: time.Unix(0, 0),
+}, {
+	format: "unix",
+	s:      "1.6e9",
+	expect: time.Unix(16e8, 0),
+}, {
+	format: "unix",
+	s:      "1.6E+9",
+	expect: time.Unix(16e8, 0),
+}, {
+	format: "unix",
+	s:      "16e8",
+	expect: time.Unix(16e8, 0),
+}, {
+	format: "unix",
+	s:      "1612345678123456789e-9",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	// A float64 can't hold this exactly.
+	format: "unix",
+	s:      "1612345678.000000001",
+	expect: time.Unix(1612345678, 1),
+}, {
+	format: "unix",
+	s:      ".5",
+	expect: time.Unix(0, 5e8),
+}, {
+	format: "unix",
+	s:      "5.",
+	expect: time.Unix(5, 0),
+}, {
+	format: "unix",
+	s:      "+5",
+	expect: time.Unix(5, 0),
+}, {
+	format: "unixmilli",
+	s:      "1612345678123.456789",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	format: "unixmilli",
+	s:      "1.612345678123456789e12",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	format: "unixmicro",
+	s:      "1612345678123456.7891",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	format: "unixnano",
+	s:      "1612345678123456789.9",
+	expect: time.Unix(1612345678, 123456789),
+}, {
+	format: "unixnano",
+	s:      "-1.5",
+	expect: time.Unix(-1, 999999998),
+}}
+
+func TestParseDecimal(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseDecimalTests {
+		f, err := lookupEpochFormat(test.format)
+		c.Assert(err, qt.IsNil)
+		got, err := f.parse(test.s)
+		c.Assert(err, qt.IsNil, qt.Commentf("%s %s", test.format, test.s))
+		c.Check(got.Equal(test.expect), qt.IsTrue, qt.Commentf("%s %s: got %v", test.format, test.s, got.UTC()))
+	}
+}
+
+func TestUnixFloatRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	f, err := lookupEpochFormat("unixfloat")
+	c.Assert(err, qt.IsNil)
+	for _, ns := range []int64{0, 1, 999999999, 1612345678123456789, 1612345678000000001, -1, -999999999, -1000000001} {
+		t0 := time.Unix(0, ns)
+		s := f.format(t0)
+		got, err := f.parse(s)
+		c.Assert(err, qt.IsNil)
+		c.Check(got.Equal(t0), qt.IsTrue, qt.Commentf("%d: %s parsed as %v", ns, s, got.UTC()))
+	}
+	c.Assert(f.format(time.Unix(-1, 999999999)), qt.Equals, "-0.000000001")
+	c.Assert(f.format(time.Unix(1612345678, 1)), qt.Equals, "1612345678.000000001")
+}
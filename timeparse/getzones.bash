@@ -20,7 +20,7 @@ make --silent tzdata.zi
 	cat << "EOF"
 	// Code xx generated by getzones.bash. DO NOT EDIT.
 
-	package main
+	package timeparse
 
 	var zoneNames = map[string]string{
 EOF
@@ -0,0 +1,223 @@
+package timeparse
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// idFormat describes an identifier format that embeds a creation time.
+type idFormat struct {
+	// parse returns the time embedded in the given identifier.
+	parse func(s string) (time.Time, error)
+	// format returns the smallest or largest identifier
+	// that can be created at the given time.
+	format func(t time.Time) (string, error)
+	// pattern matches identifiers of this kind. It is
+	// nil for identifiers that are plain numbers.
+	pattern *regexp.Regexp
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^(?i)\{?[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\}?$`)
+	ulidPattern     = regexp.MustCompile(`^(?i)[0-7][0-9a-hjkmnp-tv-z]{25}$`)
+	objectIDPattern = regexp.MustCompile(`^(?i)[0-9a-f]{24}$`)
+)
+
+// idFormats holds all the named identifier formats.
+var idFormats = map[string]*idFormat{
+	"uuid": {
+		parse:   parseUUID,
+		format:  uuidv7Formatter("-7000-8000-000000000000"),
+		pattern: uuidPattern,
+	},
+	"uuidv7max": {
+		parse:   parseUUID,
+		format:  uuidv7Formatter("-7fff-bfff-ffffffffffff"),
+		pattern: uuidPattern,
+	},
+	"ulid": {
+		parse:   parseULID,
+		format:  ulidFormatter("0000000000000000"),
+		pattern: ulidPattern,
+	},
+	"ulidmax": {
+		parse:   parseULID,
+		format:  ulidFormatter("ZZZZZZZZZZZZZZZZ"),
+		pattern: ulidPattern,
+	},
+	"objectid": {
+		parse:   parseObjectID,
+		format:  formatObjectID,
+		pattern: objectIDPattern,
+	},
+	"snowflake": snowflakeFormat(twitterEpoch),
+	"discord":   snowflakeFormat(discordEpoch),
+}
+
+// anyIDFormats holds the identifier formats that are
+// recognized by the any format, in the order they're tried.
+var anyIDFormats = []string{"uuid", "ulid", "objectid"}
+
+// Snowflake epochs in milliseconds since the Unix epoch.
+const (
+	twitterEpoch = 1288834974657
+	discordEpoch = 1420070400000
+)
+
+// lookupIDFormat returns the identifier format with the given name,
+// which may be a snowflake with a custom epoch of the form
+// "snowflake:epoch" where epoch is in milliseconds since the Unix epoch
+// or a date. It returns nil if the name isn't an identifier format.
+func lookupIDFormat(name string) (*idFormat, error) {
+	if f := idFormats[name]; f != nil {
+		return f, nil
+	}
+	if !strings.HasPrefix(name, "snowflake:") {
+		return nil, nil
+	}
+	spec := strings.TrimPrefix(name, "snowflake:")
+	if ms, err := strconv.ParseInt(spec, 10, 64); err == nil {
+		return snowflakeFormat(ms), nil
+	}
+	for _, layout := range epochLayouts {
+		if t, err := time.Parse(layout, spec); err == nil {
+			return snowflakeFormat(t.Unix()*1e3 + int64(t.Nanosecond())/1e6), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid snowflake epoch %q", spec)
+}
+
+// uuidTimeFormat is the format of the timestamp in version 1
+// and 6 UUIDs: 100ns intervals since the start of the Gregorian calendar.
+var uuidTimeFormat = &epochFormat{
+	epoch: time.Date(1582, time.October, 15, 0, 0, 0, 0, time.UTC),
+	unit:  big.NewRat(1, 1e7),
+}
+
+func parseUUID(s string) (time.Time, error) {
+	if !uuidPattern.MatchString(s) {
+		return time.Time{}, fmt.Errorf("invalid UUID %q", s)
+	}
+	b, _ := hex.DecodeString(strings.Map(func(r rune) rune {
+		if r == '-' || r == '{' || r == '}' {
+			return -1
+		}
+		return r
+	}, s))
+	if b[8]&0xc0 != 0x80 {
+		return time.Time{}, fmt.Errorf("UUID %q is not an RFC 4122 UUID", s)
+	}
+	var ts uint64
+	switch version := b[6] >> 4; version {
+	case 1:
+		ts = uint64(b[6]&0x0f)<<56 | uint64(b[7])<<48 |
+			uint64(b[4])<<40 | uint64(b[5])<<32 |
+			uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])
+	case 6:
+		ts = uint64(b[0])<<52 | uint64(b[1])<<44 | uint64(b[2])<<36 | uint64(b[3])<<28 |
+			uint64(b[4])<<20 | uint64(b[5])<<12 |
+			uint64(b[6]&0x0f)<<8 | uint64(b[7])
+	case 7:
+		ms := uint64(b[0])<<40 | uint64(b[1])<<32 | uint64(b[2])<<24 |
+			uint64(b[3])<<16 | uint64(b[4])<<8 | uint64(b[5])
+		return unixMilli(int64(ms)), nil
+	default:
+		return time.Time{}, fmt.Errorf("version %d UUID %q does not hold a time", version, s)
+	}
+	return uuidTimeFormat.time(new(big.Rat).SetInt(new(big.Int).SetUint64(ts)))
+}
+
+func uuidv7Formatter(suffix string) func(t time.Time) (string, error) {
+	return func(t time.Time) (string, error) {
+		ms, err := millis48(t)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%08x-%04x%s", ms>>16, ms&0xffff, suffix), nil
+	}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func parseULID(s string) (time.Time, error) {
+	if !ulidPattern.MatchString(s) {
+		return time.Time{}, fmt.Errorf("invalid ULID %q", s)
+	}
+	var ms int64
+	for _, c := range strings.ToUpper(s[:10]) {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, c))
+	}
+	return unixMilli(ms), nil
+}
+
+func ulidFormatter(suffix string) func(t time.Time) (string, error) {
+	return func(t time.Time) (string, error) {
+		ms, err := millis48(t)
+		if err != nil {
+			return "", err
+		}
+		var buf [10]byte
+		for i := len(buf) - 1; i >= 0; i-- {
+			buf[i] = crockford[ms&31]
+			ms >>= 5
+		}
+		return string(buf[:]) + suffix, nil
+	}
+}
+
+func parseObjectID(s string) (time.Time, error) {
+	if !objectIDPattern.MatchString(s) {
+		return time.Time{}, fmt.Errorf("invalid ObjectId %q", s)
+	}
+	secs, _ := strconv.ParseUint(s[:8], 16, 32)
+	return time.Unix(int64(secs), 0), nil
+}
+
+func formatObjectID(t time.Time) (string, error) {
+	secs := t.Unix()
+	if secs < 0 || secs >= 1<<32 {
+		return "", fmt.Errorf("time %v out of range for ObjectId", t)
+	}
+	return fmt.Sprintf("%08x0000000000000000", secs), nil
+}
+
+// snowflakeFormat returns the format for Twitter-style snowflake
+// identifiers with the given epoch in milliseconds since the Unix epoch.
+// The time is held in the 41 bits below the sign bit.
+func snowflakeFormat(epoch int64) *idFormat {
+	return &idFormat{
+		parse: func(s string) (time.Time, error) {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid snowflake %q", s)
+			}
+			return unixMilli(int64(id>>22) + epoch), nil
+		},
+		format: func(t time.Time) (string, error) {
+			ms := t.Unix()*1e3 + int64(t.Nanosecond())/1e6 - epoch
+			if ms < 0 || ms >= 1<<41 {
+				return "", fmt.Errorf("time %v out of range for snowflake", t)
+			}
+			return strconv.FormatUint(uint64(ms)<<22, 10), nil
+		},
+	}
+}
+
+// millis48 returns t as the 48-bit millisecond timestamp
+// used by ULIDs and version 7 UUIDs.
+func millis48(t time.Time) (int64, error) {
+	ms := t.Unix()*1e3 + int64(t.Nanosecond())/1e6
+	if ms < 0 || ms >= 1<<48 {
+		return 0, fmt.Errorf("time %v out of range for 48-bit millisecond timestamp", t)
+	}
+	return ms, nil
+}
+
+func unixMilli(ms int64) time.Time {
+	return time.Unix(ms/1e3, (ms%1e3)*1e6)
+}
@@ -0,0 +1,222 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// The RFC 9562 test vectors for versions 1, 6 and 7 all hold
+// the time 2022-02-22T14:22:22-05:00.
+var rfc9562Time = time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC)
+
+var parseIDTests = []struct {
+	testName string
+	format   string
+	s        string
+	expect   time.Time
+}{{
+	testName: "uuid-v1",
+	format:   "uuid",
+	s:        "C232AB00-9414-11EC-B3C8-9F6BDECED846",
+	expect:   rfc9562Time,
+}, {
+	testName: "uuid-v6",
+	format:   "uuid",
+	s:        "1EC9414C-232A-6B00-B3C8-9F6BDECED846",
+	expect:   rfc9562Time,
+}, {
+	testName: "uuid-v7",
+	format:   "uuid",
+	s:        "017F22E2-79B0-7CC3-98C4-DC0C0C07398F",
+	expect:   rfc9562Time,
+}, {
+	testName: "uuid-v7-lower-case-braces",
+	format:   "uuid",
+	s:        "{017f22e2-79b0-7cc3-98c4-dc0c0c07398f}",
+	expect:   rfc9562Time,
+}, {
+	testName: "uuid-v7-milliseconds",
+	format:   "uuid",
+	s:        "018c3a2b-1d4f-7000-8000-000000000000",
+	expect:   time.Date(2023, 12, 5, 13, 29, 13, 295e6, time.UTC),
+}, {
+	// The example from the ULID specification.
+	testName: "ulid",
+	format:   "ulid",
+	s:        "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	expect:   time.Date(2016, 7, 30, 23, 54, 10, 259e6, time.UTC),
+}, {
+	testName: "ulid-lower-case",
+	format:   "ulid",
+	s:        "01arz3ndektsv4rrffq69g5fav",
+	expect:   time.Date(2016, 7, 30, 23, 54, 10, 259e6, time.UTC),
+}, {
+	// The example from the MongoDB documentation.
+	testName: "objectid",
+	format:   "objectid",
+	s:        "507f1f77bcf86cd799439011",
+	expect:   time.Date(2012, 10, 17, 21, 13, 27, 0, time.UTC),
+}, {
+	// The example from the Discord API documentation.
+	testName: "discord",
+	format:   "discord",
+	s:        "175928847299117063",
+	expect:   time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC),
+}, {
+	testName: "snowflake-custom-epoch",
+	format:   "snowflake:1420070400000",
+	s:        "175928847299117063",
+	expect:   time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC),
+}, {
+	testName: "snowflake-custom-epoch-date",
+	format:   "snowflake:2015-01-01",
+	s:        "175928847299117063",
+	expect:   time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC),
+}, {
+	testName: "snowflake-epoch",
+	format:   "snowflake",
+	s:        "0",
+	expect:   time.Date(2010, 11, 4, 1, 42, 54, 657e6, time.UTC),
+}}
+
+func TestParseID(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseIDTests {
+		c.Run(test.testName, func(c *qt.C) {
+			f, err := lookupIDFormat(test.format)
+			c.Assert(err, qt.IsNil)
+			c.Assert(f, qt.Not(qt.IsNil))
+			got, err := f.parse(test.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got.Equal(test.expect), qt.IsTrue, qt.Commentf("got %v", got.UTC()))
+		})
+	}
+}
+
+var formatIDTests = []struct {
+	format string
+	t      time.Time
+	expect string
+}{{
+	format: "uuid",
+	t:      rfc9562Time,
+	expect: "017f22e2-79b0-7000-8000-000000000000",
+}, {
+	format: "uuidv7max",
+	t:      rfc9562Time,
+	expect: "017f22e2-79b0-7fff-bfff-ffffffffffff",
+}, {
+	// Sub-millisecond parts are dropped.
+	format: "uuid",
+	t:      time.Date(2023, 12, 5, 13, 29, 13, 295999999, time.UTC),
+	expect: "018c3a2b-1d4f-7000-8000-000000000000",
+}, {
+	format: "ulid",
+	t:      time.Date(2016, 7, 30, 23, 54, 10, 259e6, time.UTC),
+	expect: "01ARZ3NDEK0000000000000000",
+}, {
+	format: "ulidmax",
+	t:      time.Date(2016, 7, 30, 23, 54, 10, 259e6, time.UTC),
+	expect: "01ARZ3NDEKZZZZZZZZZZZZZZZZ",
+}, {
+	format: "objectid",
+	t:      time.Date(2012, 10, 17, 21, 13, 27, 0, time.UTC),
+	expect: "507f1f770000000000000000",
+}, {
+	format: "discord",
+	t:      time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC),
+	expect: "175928847298985984",
+}, {
+	format: "snowflake",
+	t:      time.Date(2010, 11, 4, 1, 42, 54, 657e6, time.UTC),
+	expect: "0",
+}}
+
+func TestFormatID(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range formatIDTests {
+		f, err := lookupIDFormat(test.format)
+		c.Assert(err, qt.IsNil)
+		got, err := f.format(test.t)
+		c.Assert(err, qt.IsNil)
+		c.Check(got, qt.Equals, test.expect, qt.Commentf("%s %v", test.format, test.t))
+		// The generated identifier holds the time, to the millisecond
+		// (or second, for ObjectIds).
+		back, err := f.parse(got)
+		c.Assert(err, qt.IsNil)
+		c.Check(back.Equal(test.t.Truncate(time.Millisecond)), qt.IsTrue, qt.Commentf("%s %v", test.format, back))
+	}
+}
+
+func TestIDErrors(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range []struct {
+		format      string
+		s           string
+		expectError string
+	}{{
+		format:      "uuid",
+		s:           "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		expectError: `version 4 UUID ".*" does not hold a time`,
+	}, {
+		format:      "uuid",
+		s:           "017f22e2-79b0-7cc3-08c4-dc0c0c07398f",
+		expectError: `UUID ".*" is not an RFC 4122 UUID`,
+	}, {
+		format:      "uuid",
+		s:           "017f22e2-79b0-7cc3",
+		expectError: `invalid UUID "017f22e2-79b0-7cc3"`,
+	}, {
+		// ULIDs can't start with a digit above 7.
+		format:      "ulid",
+		s:           "81ARZ3NDEKTSV4RRFFQ69G5FAV",
+		expectError: `invalid ULID "81ARZ3NDEKTSV4RRFFQ69G5FAV"`,
+	}, {
+		format:      "ulid",
+		s:           "01ARZ3NDEKTSV4RRFFQ69G5FAU",
+		expectError: `invalid ULID "01ARZ3NDEKTSV4RRFFQ69G5FAU"`,
+	}, {
+		format:      "objectid",
+		s:           "507f1f77bcf86cd79943901",
+		expectError: `invalid ObjectId "507f1f77bcf86cd79943901"`,
+	}, {
+		format:      "snowflake",
+		s:           "-1",
+		expectError: `invalid snowflake "-1"`,
+	}} {
+		f, err := lookupIDFormat(test.format)
+		c.Assert(err, qt.IsNil)
+		_, err = f.parse(test.s)
+		c.Check(err, qt.ErrorMatches, test.expectError, qt.Commentf("%s %s", test.format, test.s))
+	}
+	for _, test := range []struct {
+		format      string
+		t           time.Time
+		expectError string
+	}{{
+		format:      "objectid",
+		t:           time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+		expectError: `time .* out of range for ObjectId`,
+	}, {
+		format:      "objectid",
+		t:           time.Date(2106, 2, 8, 0, 0, 0, 0, time.UTC),
+		expectError: `time .* out of range for ObjectId`,
+	}, {
+		format:      "uuid",
+		t:           time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+		expectError: `time .* out of range for 48-bit millisecond timestamp`,
+	}, {
+		format:      "discord",
+		t:           time.Date(2014, 12, 31, 0, 0, 0, 0, time.UTC),
+		expectError: `time .* out of range for snowflake`,
+	}} {
+		f, err := lookupIDFormat(test.format)
+		c.Assert(err, qt.IsNil)
+		_, err = f.format(test.t)
+		c.Check(err, qt.ErrorMatches, test.expectError, qt.Commentf("%s %v", test.format, test.t))
+	}
+	_, err := lookupIDFormat("snowflake:yesterday")
+	c.Assert(err, qt.ErrorMatches, `invalid snowflake epoch "yesterday"`)
+}
@@ -0,0 +1,270 @@
+package timeparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The ixdtf format is the Internet Extended Date/Time Format of
+// RFC 9557, as used by JavaScript's Temporal and java.time: an RFC 3339
+// time followed by a time zone name and other annotations in brackets,
+// for example "2024-03-10T03:30:00-04:00[America/New_York]".
+
+// OffsetPolicy specifies how an ixdtf time whose UTC offset
+// doesn't agree with its time zone is interpreted. The names
+// are those used by JavaScript's Temporal.
+type OffsetPolicy int
+
+const (
+	// OffsetReject treats the disagreement as an error.
+	OffsetReject OffsetPolicy = iota
+	// OffsetUse uses the offset to find the instant,
+	// which is then shown in the time zone.
+	OffsetUse
+	// OffsetIgnore ignores the offset altogether and interprets
+	// the date and time as a wall clock time in the time zone.
+	OffsetIgnore
+	// OffsetPrefer uses the offset when it's valid for the
+	// time zone and otherwise ignores it.
+	OffsetPrefer
+)
+
+var offsetPolicies = []string{
+	OffsetReject: "reject",
+	OffsetUse:    "use",
+	OffsetIgnore: "ignore",
+	OffsetPrefer: "prefer",
+}
+
+// ParseOffsetPolicy returns the offset policy with the
+// given name: reject, use, ignore or prefer.
+func ParseOffsetPolicy(s string) (OffsetPolicy, error) {
+	for p, name := range offsetPolicies {
+		if s == name {
+			return OffsetPolicy(p), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown offset policy %q (valid policies are reject, use, ignore and prefer)", s)
+}
+
+// String returns the name of the policy.
+func (p OffsetPolicy) String() string {
+	if p < 0 || int(p) >= len(offsetPolicies) {
+		return fmt.Sprintf("OffsetPolicy(%d)", int(p))
+	}
+	return offsetPolicies[p]
+}
+
+// ixdtfPattern matches an ixdtf time. The submatches are the date,
+// the time, the offset and the annotations.
+var ixdtfPattern = regexp.MustCompile(`^` + ixdtfTimeSource + `((?:` + ixdtfAnnotationSource + `)*)$`)
+
+const (
+	ixdtfTimeSource = `([0-9]{4}-[0-9]{2}-[0-9]{2})` +
+		`(?:[Tt ]([0-9]{2}:[0-9]{2}(?::[0-9]{2}(?:[.,][0-9]{1,9})?)?)` +
+		`([Zz]|[-+][0-9]{2}:[0-9]{2})?)?`
+	ixdtfAnnotationSource = `\[!?[^\[\]\s]+\]`
+)
+
+// ixdtfAnyPattern matches the ixdtf times recognized by the any
+// format, which must have at least one annotation.
+const ixdtfAnyPattern = `(?:` + ixdtfTimeSource + `(?:` + ixdtfAnnotationSource + `)+)`
+
+var annotationKeyPattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+
+// isIXDTF reports whether s looks like an ixdtf time
+// with annotations, as opposed to a plain RFC 3339 time.
+func isIXDTF(s string) bool {
+	return strings.HasSuffix(s, "]") && ixdtfPattern.MatchString(s)
+}
+
+// parseIXDTF parses s as an ixdtf time. Times with neither
+// an offset nor a time zone are interpreted in loc.
+func (p *Parser) parseIXDTF(s string, loc *time.Location) (time.Time, error) {
+	m := ixdtfPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as ixdtf", s)
+	}
+	date, clock, offset, annotations := m[1], m[2], m[3], m[4]
+	wall, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date in %q: %v", s, err)
+	}
+	year, month, day := wall.Date()
+	var hour, min, sec, nsec int
+	if clock != "" {
+		clock = strings.Replace(clock, ",", ".", 1)
+		layout := "15:04"
+		if len(clock) > len("15:04") {
+			layout = "15:04:05.999999999"
+		}
+		c, err := time.Parse(layout, clock)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time in %q: %v", s, err)
+		}
+		hour, min, sec, nsec = c.Hour(), c.Minute(), c.Second(), c.Nanosecond()
+	}
+	zoneName, critical, err := parseAnnotations(annotations)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var zone *time.Location
+	if zoneName != "" {
+		zone, err = ixdtfZone(zoneName)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	// An offset of Z or -00:00 means that the UTC time is
+	// known but the local offset is not.
+	unknownLocal := offset == "Z" || offset == "z" || offset == "-00:00"
+	switch {
+	case offset == "" && zone == nil:
+		return time.Date(year, month, day, hour, min, sec, nsec, loc), nil
+	case offset == "":
+		return time.Date(year, month, day, hour, min, sec, nsec, zone), nil
+	case unknownLocal:
+		t := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+		if zone != nil {
+			t = t.In(zone)
+		}
+		return t, nil
+	}
+	off, _ := parseOffset(offset)
+	t := time.Date(year, month, day, hour, min, sec, nsec, time.FixedZone("", off))
+	if zone == nil {
+		return t, nil
+	}
+	if p.OffsetPolicy == OffsetIgnore && !critical {
+		return time.Date(year, month, day, hour, min, sec, nsec, zone), nil
+	}
+	t = t.In(zone)
+	if _, zoff := t.Zone(); zoff != off {
+		mismatch := fmt.Errorf("offset %s does not match time zone %s (%s) in %q", offset, zoneName, formatOffset(zoff), s)
+		switch {
+		case critical, p.OffsetPolicy == OffsetReject:
+			return time.Time{}, mismatch
+		case p.OffsetPolicy == OffsetPrefer:
+			return time.Date(year, month, day, hour, min, sec, nsec, zone), nil
+		}
+	}
+	return t, nil
+}
+
+// parseAnnotations parses the bracketed annotations that follow an
+// ixdtf time. It returns the time zone, if there is one, and reports
+// whether the time zone is marked critical. Unknown annotations
+// are ignored unless they're marked critical.
+func parseAnnotations(s string) (zone string, critical bool, err error) {
+	for i := 0; s != ""; i++ {
+		end := strings.IndexByte(s, ']')
+		a := s[1:end]
+		s = s[end+1:]
+		isCritical := strings.HasPrefix(a, "!")
+		a = strings.TrimPrefix(a, "!")
+		eq := strings.IndexByte(a, '=')
+		if eq < 0 {
+			if i > 0 {
+				return "", false, fmt.Errorf("time zone annotation [%s] must come first", a)
+			}
+			zone, critical = a, isCritical
+			continue
+		}
+		key, value := a[:eq], a[eq+1:]
+		if !annotationKeyPattern.MatchString(key) || value == "" {
+			return "", false, fmt.Errorf("invalid annotation [%s]", a)
+		}
+		// Calendars don't affect the instant, so
+		// u-ca annotations can always be ignored.
+		if isCritical && key != "u-ca" {
+			return "", false, fmt.Errorf("unsupported critical annotation [!%s]", a)
+		}
+	}
+	return zone, critical, nil
+}
+
+// ixdtfZone returns the time zone for the
+// time zone annotation name, which is either
+// a time zone name or a UTC offset.
+func ixdtfZone(name string) (*time.Location, error) {
+	if name[0] == '+' || name[0] == '-' {
+		off, err := parseOffset(name)
+		if err != nil {
+			return nil, err
+		}
+		return time.FixedZone(name, off), nil
+	}
+	return time.LoadLocation(name)
+}
+
+// parseOffset parses a UTC offset of the form ±hh:mm.
+func parseOffset(s string) (int, error) {
+	if len(s) != len("+00:00") || s[3] != ':' || s[0] != '+' && s[0] != '-' {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	h, err1 := strconv.Atoi(s[1:3])
+	m, err2 := strconv.Atoi(s[4:])
+	if err1 != nil || err2 != nil || h > 23 || m > 59 {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	off := h*3600 + m*60
+	if s[0] == '-' {
+		off = -off
+	}
+	return off, nil
+}
+
+// formatOffset formats an offset in seconds as ±hh:mm.
+func formatOffset(off int) string {
+	sign := '+'
+	if off < 0 {
+		sign, off = '-', -off
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, off/3600, off/60%60)
+}
+
+// formatIXDTF formats t as an ixdtf time with its time zone in
+// brackets. When t's location has no time zone name, its UTC
+// offset is used instead.
+func formatIXDTF(t time.Time) string {
+	name := t.Location().String()
+	if t.Location() == time.Local {
+		name = localZoneName()
+	}
+	_, off := t.Zone()
+	if name == "" || t.Location() != time.UTC && name != "UTC" && !isZoneName(name) {
+		name = formatOffset(off)
+	}
+	return t.Format("2006-01-02T15:04:05.999999999-07:00") + "[" + name + "]"
+}
+
+// isZoneName reports whether name is a known time zone name.
+func isZoneName(name string) bool {
+	_, ok := zoneNames[name]
+	return ok
+}
+
+// localZoneName returns the name of the local time zone,
+// or the empty string if it's not known.
+func localZoneName() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if isZoneName(tz) {
+			return tz
+		}
+		return ""
+	}
+	target, err := filepath.EvalSymlinks("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	const dir = "zoneinfo/"
+	if i := strings.LastIndex(target, dir); i >= 0 && isZoneName(target[i+len(dir):]) {
+		return target[i+len(dir):]
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/rogpeppe/godate/timeformat"
+)
+
+var parseIXDTFTests = []struct {
+	testName    string
+	policy      OffsetPolicy
+	s           string
+	expect      time.Time
+	expectZone  string
+	expectError string
+}{{
+	testName:   "zone",
+	s:          "2024-03-10T03:30:00-04:00[America/New_York]",
+	expect:     time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+	expectZone: "America/New_York",
+}, {
+	testName:   "no-offset",
+	s:          "2024-03-10T12:00[Europe/Paris]",
+	expect:     time.Date(2024, 3, 10, 11, 0, 0, 0, time.UTC),
+	expectZone: "Europe/Paris",
+}, {
+	testName:   "date-only",
+	s:          "2024-03-10[Europe/Paris]",
+	expect:     time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC),
+	expectZone: "Europe/Paris",
+}, {
+	testName:   "utc-offset-unknown",
+	s:          "2024-03-10T12:00:00Z[Asia/Tokyo]",
+	expect:     time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	expectZone: "Asia/Tokyo",
+}, {
+	testName:   "offset-annotation",
+	s:          "2024-03-10T12:00:00.5+05:30[+05:30]",
+	expect:     time.Date(2024, 3, 10, 6, 30, 0, 5e8, time.UTC),
+	expectZone: "+05:30",
+}, {
+	testName:   "elective-annotations",
+	s:          "2024-03-10T12:00:00Z[UTC][u-ca=hebrew][x-foo=bar]",
+	expect:     time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	expectZone: "UTC",
+}, {
+	testName:   "critical-calendar",
+	s:          "2024-03-10T12:00:00Z[!u-ca=iso8601]",
+	expect:     time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	expectZone: "UTC",
+}, {
+	testName:   "no-annotations",
+	s:          "2024-03-10T12:00:00+01:00",
+	expect:     time.Date(2024, 3, 10, 11, 0, 0, 0, time.UTC),
+	expectZone: "",
+}, {
+	testName:    "mismatch-reject",
+	s:           "2024-03-10T02:30:00-05:00[America/New_York]",
+	expectError: `offset -05:00 does not match time zone America/New_York \(-04:00\) in ".*"`,
+}, {
+	testName:   "mismatch-use",
+	policy:     OffsetUse,
+	s:          "2024-03-10T02:30:00-05:00[America/New_York]",
+	expect:     time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+	expectZone: "America/New_York",
+}, {
+	testName:   "mismatch-prefer",
+	policy:     OffsetPrefer,
+	s:          "2024-03-10T12:00:00-05:00[America/New_York]",
+	expect:     time.Date(2024, 3, 10, 16, 0, 0, 0, time.UTC),
+	expectZone: "America/New_York",
+}, {
+	testName:    "mismatch-critical",
+	policy:      OffsetUse,
+	s:           "2024-03-10T02:30:00-05:00[!America/New_York]",
+	expectError: `offset -05:00 does not match time zone America/New_York \(-04:00\) in ".*"`,
+}, {
+	// The second 01:30 of the day, when daylight saving time has ended.
+	testName:   "ambiguous-prefer",
+	policy:     OffsetPrefer,
+	s:          "2024-11-03T01:30:00-05:00[America/New_York]",
+	expect:     time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+	expectZone: "America/New_York",
+}, {
+	testName:   "ambiguous-ignore",
+	policy:     OffsetIgnore,
+	s:          "2024-11-03T01:30:00-05:00[America/New_York]",
+	expect:     time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+	expectZone: "America/New_York",
+}, {
+	testName:    "critical-unknown",
+	s:           "2024-03-10T12:00[Europe/Paris][!x-foo=bar]",
+	expectError: `unsupported critical annotation \[!x-foo=bar\]`,
+}, {
+	testName:    "zone-not-first",
+	s:           "2024-03-10T12:00[u-ca=hebrew][Europe/Paris]",
+	expectError: `time zone annotation \[Europe/Paris\] must come first`,
+}, {
+	testName:    "unknown-zone",
+	s:           "2024-03-10T12:00[Nowhere/Zone]",
+	expectError: `unknown time zone Nowhere/Zone`,
+}, {
+	testName:    "invalid-date",
+	s:           "2024-02-30T12:00[UTC]",
+	expectError: `invalid date in .*`,
+}}
+
+func TestParseIXDTF(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseIXDTFTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := Parser{
+				Format:       "ixdtf",
+				Location:     time.UTC,
+				OffsetPolicy: test.policy,
+			}
+			tm, format, err := p.Parse(test.s)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(format, qt.Equals, "ixdtf")
+			c.Assert(tm.Equal(test.expect), qt.IsTrue, qt.Commentf("got %v", tm))
+			c.Assert(tm.Location().String(), qt.Equals, test.expectZone)
+		})
+	}
+}
+
+func TestParseAnyIXDTF(t *testing.T) {
+	c := qt.New(t)
+	p := Parser{Location: time.UTC}
+	tm, format, err := p.Parse("2024-03-10T03:30:00-04:00[America/New_York]")
+	c.Assert(err, qt.IsNil)
+	c.Assert(format, qt.Equals, "ixdtf")
+	c.Assert(tm.Equal(time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC)), qt.IsTrue)
+	c.Assert(tm.Location().String(), qt.Equals, "America/New_York")
+
+	// Errors in ixdtf times are reported as such.
+	_, _, err = p.Parse("2024-03-10T02:30:00-05:00[America/New_York]")
+	c.Assert(err, qt.ErrorMatches, `offset -05:00 does not match .*`)
+
+	pat, err := p.Pattern()
+	c.Assert(err, qt.IsNil)
+	line := "at 2024-03-10T03:30:00-04:00[America/New_York][u-ca=iso8601] exactly"
+	c.Assert(pat.FindString(line), qt.Equals, "2024-03-10T03:30:00-04:00[America/New_York][u-ca=iso8601]")
+}
+
+func TestFormatIXDTF(t *testing.T) {
+	c := qt.New(t)
+	newYork, err := time.LoadLocation("America/New_York")
+	c.Assert(err, qt.IsNil)
+	t0 := time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC)
+	for _, test := range []struct {
+		t      time.Time
+		expect string
+	}{{
+		t:      t0.In(newYork),
+		expect: "2024-03-10T03:30:00-04:00[America/New_York]",
+	}, {
+		t:      t0,
+		expect: "2024-03-10T07:30:00+00:00[UTC]",
+	}, {
+		t:      t0.Add(time.Millisecond).In(time.FixedZone("", -3*3600)),
+		expect: "2024-03-10T04:30:00.001-03:00[-03:00]",
+	}, {
+		t:      t0.In(time.FixedZone("XYZ", 5*3600+1800)),
+		expect: "2024-03-10T13:00:00+05:30[+05:30]",
+	}} {
+		got, err := Format(test.t, "ixdtf", timeformat.Style{})
+		c.Assert(err, qt.IsNil)
+		c.Check(got, qt.Equals, test.expect)
+	}
+}
+
+func TestParseOffsetPolicy(t *testing.T) {
+	c := qt.New(t)
+	for _, name := range []string{"reject", "use", "ignore", "prefer"} {
+		policy, err := ParseOffsetPolicy(name)
+		c.Assert(err, qt.IsNil)
+		c.Assert(policy.String(), qt.Equals, name)
+	}
+	_, err := ParseOffsetPolicy("bogus")
+	c.Assert(err, qt.ErrorMatches, `unknown offset policy "bogus" .*`)
+}
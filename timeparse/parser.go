@@ -0,0 +1,524 @@
+// Package timeparse implements the forgiving time parsing used by the
+// godate command: times in Go layouts or named formats (including
+// Unix times, epoch counts, spreadsheet serial dates and identifiers
+// with embedded times), the "any" format that guesses the format of
+// a time, date deltas and time zone matching.
+package timeparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/godate/timeformat"
+)
+
+var knownFormats = map[string]string{
+	"ansic":       time.ANSIC,
+	"git":         "Mon Jan _2 15:04:05 2006 -0700",
+	"unixdate":    time.UnixDate,
+	"rubydate":    time.RubyDate,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"rfc850":      time.RFC850,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"kitchen":     time.Kitchen,
+	"stamp":       time.Stamp,
+	"stampmilli":  time.StampMilli,
+	"stampmicro":  time.StampMicro,
+	"stampnano":   time.StampNano,
+	"go":          "2006-01-02 15:04:05.999999999 -0700 MST",
+	"unix":        "custom",
+	"unixmilli":   "custom",
+	"unixmicro":   "custom",
+	"unixnano":    "custom",
+	"unixfloat":   "custom",
+	"ntp":         "custom",
+	"ntp64":       "custom",
+	"filetime":    "custom",
+	"dotnet":      "custom",
+	"cocoa":       "custom",
+	"webkit":      "custom",
+	"gps":         "custom",
+	"uuid":        "custom",
+	"uuidv7max":   "custom",
+	"ulid":        "custom",
+	"ulidmax":     "custom",
+	"objectid":    "custom",
+	"snowflake":   "custom",
+	"discord":     "custom",
+	"excel":       "custom",
+	"excel1904":   "custom",
+	"lotus":       "custom",
+	"libreoffice": "custom",
+	"ixdtf":       "custom",
+	"any":         "custom",
+}
+
+// Formats returns a map from the name of each known format to its
+// layout. Formats that are not implemented with a layout, such as
+// unix, have the layout "custom".
+func Formats() map[string]string {
+	formats := make(map[string]string, len(knownFormats))
+	for name, layout := range knownFormats {
+		formats[name] = layout
+	}
+	return formats
+}
+
+// LookupFormat returns the layout for the given format, which may
+// be a layout or the name of a known format (case-insensitive). If the
+// format is not implemented with a layout, it returns the canonical
+// format name and reports true.
+//
+// As well as the names returned by Formats, it accepts the
+// parameterized formats "snowflake:epoch", "unixfloat:N"
+// and "epoch:date/unit".
+func LookupFormat(format string) (layout string, custom bool, err error) {
+	name := strings.ToLower(format)
+	if format1, ok := knownFormats[name]; ok {
+		if format1 == "custom" {
+			return name, true, nil
+		}
+		return format1, false, nil
+	}
+	if strings.HasPrefix(name, "snowflake:") {
+		if _, err := lookupIDFormat(name); err != nil {
+			return "", false, err
+		}
+		return name, true, nil
+	}
+	if strings.HasPrefix(name, "unixfloat:") {
+		if _, err := lookupEpochFormat(name); err != nil {
+			return "", false, err
+		}
+		return name, true, nil
+	}
+	if strings.HasPrefix(name, "epoch:") {
+		format = "epoch:" + format[len("epoch:"):]
+		if _, err := lookupEpochFormat(format); err != nil {
+			return "", false, err
+		}
+		return format, true, nil
+	}
+	return format, false, nil
+}
+
+// Parser parses times. The zero value parses times in
+// the any format in the local time zone.
+type Parser struct {
+	// Format holds the format of the times, as accepted by LookupFormat.
+	// If it's empty, the any format is used.
+	Format string
+
+	// Location holds the time zone that times without
+	// a time zone are interpreted in. If it's nil,
+	// the local time zone is used.
+	Location *time.Location
+
+	// Now holds the current time, which is used for the time "now"
+	// and to fill in the parts of a time that are more significant
+	// than any in its layout. If it's zero, time.Now is used.
+	Now time.Time
+
+	// Abs specifies that missing parts of times should
+	// be left zero rather than filled in from Now.
+	Abs bool
+
+	// Style holds the locale and calendar used for layouts.
+	Style timeformat.Style
+
+	// AnyLayouts holds layouts to be tried, in order, by
+	// the any format before the built-in layouts.
+	AnyLayouts []string
+
+	// Serial holds the name of a spreadsheet date system
+	// (for example "excel"). If it's not empty, the any format
+	// interprets numbers with no more than five integer digits
+	// as serial dates in that system.
+	Serial string
+
+	// InlineZones specifies that a time that can't otherwise
+	// be parsed may be followed by @ or a space and the name
+	// of a time zone, for example "09:00@tokyo" or
+	// "2024-03-01 10:00 Europe/Paris", in which case it's
+	// interpreted in that zone rather than Location.
+	InlineZones bool
+
+	// OffsetPolicy specifies how ixdtf times whose UTC offset
+	// doesn't match their time zone are interpreted.
+	OffsetPolicy OffsetPolicy
+
+	// LoadLocation is used to find inline time zones.
+	// If it's nil, the LoadLocation function is used.
+	LoadLocation func(name string) (*time.Location, error)
+
+	// checked holds the format found by the most recent
+	// successful call to Check.
+	checked *checkedFormat
+}
+
+// checkedFormat holds a parser's format once it's been validated.
+type checkedFormat struct {
+	// format and serial hold the Format and Serial fields
+	// of the parser that was checked.
+	format string
+	serial string
+
+	// layout and custom hold the results of LookupFormat.
+	layout string
+	custom bool
+
+	// parse holds the function that parses times
+	// in identifier and epoch formats.
+	parse func(s string) (time.Time, error)
+}
+
+// Check returns an error if the parser's format or
+// serial date system are not valid. It should be called after
+// the parser's fields are set, so that Parse doesn't need
+// to validate the format each time it's called.
+func (p *Parser) Check() error {
+	f, err := p.check()
+	if err != nil {
+		return err
+	}
+	p.checked = f
+	return nil
+}
+
+func (p *Parser) check() (*checkedFormat, error) {
+	if _, ok := serialSystems[p.Serial]; p.Serial != "" && !ok {
+		return nil, fmt.Errorf("unknown spreadsheet date system %q", p.Serial)
+	}
+	layout, custom, err := LookupFormat(p.format())
+	if err != nil {
+		return nil, err
+	}
+	f := &checkedFormat{
+		format: p.Format,
+		serial: p.Serial,
+		layout: layout,
+		custom: custom,
+	}
+	if _, ok := serialSystems[layout]; custom && !ok && layout != "any" && layout != "ixdtf" {
+		if f.parse, err = customParser(layout); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Parse parses the time s. As well as the time, it returns the format
+// that the time was parsed with, which is useful to know when the time
+// was parsed with the any format. The time "now" is always
+// recognized as the current time.
+func (p *Parser) Parse(s string) (t time.Time, format string, err error) {
+	f := p.checked
+	if f == nil || f.format != p.Format || f.serial != p.Serial {
+		// The parser hasn't been checked, or it's
+		// been changed since it was.
+		if f, err = p.check(); err != nil {
+			return time.Time{}, "", err
+		}
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	now := p.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	t, format, err = p.parse(f, s, loc, now)
+	if err == nil || !p.InlineZones {
+		return t, format, err
+	}
+	if i := strings.LastIndexByte(s, '@'); i > 0 {
+		loc, zerr := p.loadLocation(s[i+1:])
+		if zerr != nil {
+			return time.Time{}, "", fmt.Errorf("%v (%v)", err, zerr)
+		}
+		return p.parse(f, s[:i], loc, now)
+	}
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		if loc, zerr := p.loadLocation(s[i+1:]); zerr == nil {
+			if t, format, zerr := p.parse(f, strings.TrimRight(s[:i], " "), loc, now); zerr == nil {
+				return t, format, nil
+			}
+		}
+	}
+	return t, format, err
+}
+
+func (p *Parser) loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("empty time zone name")
+	}
+	if p.LoadLocation != nil {
+		return p.LoadLocation(name)
+	}
+	return LoadLocation(name)
+}
+
+// parse parses s in the checked format f in the location loc.
+func (p *Parser) parse(f *checkedFormat, s string, loc *time.Location, now time.Time) (time.Time, string, error) {
+	now = now.In(loc)
+	if s == "now" {
+		return now, "now", nil
+	}
+	if f.parse != nil {
+		t, err := f.parse(s)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		return t.In(loc), f.layout, nil
+	}
+	if f.custom {
+		return p.parseCustom(f.layout, s, loc, now)
+	}
+	t, err := p.Style.ParseInLocation(f.layout, s, loc)
+	if err != nil || p.Abs {
+		return t, p.Format, err
+	}
+	return relativeTime(t, timeformat.LayoutComponents(f.layout), now), p.Format, nil
+}
+
+func (p *Parser) format() string {
+	if p.Format == "" {
+		return "any"
+	}
+	return p.Format
+}
+
+var componentsBySignificance = []timeformat.Components{
+	timeformat.Year,
+	timeformat.Month,
+	timeformat.Day,
+	timeformat.Hour,
+	timeformat.Minute,
+	timeformat.Second,
+}
+
+func relativeTime(t time.Time, components timeformat.Components, now time.Time) time.Time {
+	td := timeformat.TimeDate(t)
+	nowd := timeformat.TimeDate(now)
+	var toSet timeformat.Components
+	for _, c := range componentsBySignificance {
+		if components&c != 0 {
+			break
+		}
+		toSet |= c
+	}
+	td.SetComponents(nowd, toSet)
+	return td.Time()
+}
+
+// parseCustom parses s in the given custom format. Like Parse, it
+// also returns the format used, which is only different from format
+// when format is "any".
+func (p *Parser) parseCustom(format, s string, tz *time.Location, now time.Time) (time.Time, string, error) {
+	if format == "any" {
+		return p.parseAny(s, tz, now)
+	}
+	if format == "ixdtf" {
+		t, err := p.parseIXDTF(s, tz)
+		return t, format, err
+	}
+	if sys, ok := serialSystems[format]; ok {
+		t, err := parseSerial(sys, s, tz)
+		return t, format, err
+	}
+	parse, err := customParser(format)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	t, err := parse(s)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return t.In(tz), format, nil
+}
+
+// customParser returns the function that parses times in
+// the given identifier or epoch format.
+func customParser(format string) (func(s string) (time.Time, error), error) {
+	idf, err := lookupIDFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if idf != nil {
+		return idf.parse, nil
+	}
+	f, err := lookupEpochFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		panic("unknown custom time format")
+	}
+	return f.parse, nil
+}
+
+var anyFormats = []string{
+	knownFormats["git"],
+	time.RFC3339,
+	knownFormats["unixdate"],
+	"2006",
+	"2006-01-02",
+	"2006-01-02 15:04:05Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"01-02 15:04",
+	"Jan 2",
+	"Jan 2 15:04",
+	"Jan 2 15:04:05",
+	"2 Jan",
+	"2 Jan 15:04",
+	"2 Jan 15:04:05",
+	"15:04",
+	"15:04:05",
+	"3pm",
+	"3PM",
+	"3:04pm",
+	"3:04PM",
+	"3:04:05pm",
+	"3:04:05PM",
+	"2006Q%Q",
+	"2006-Q%Q",
+	"2006 Q%Q",
+	"Q%Q 2006",
+	"Q%Q",
+	"FY%F",
+	"FY%f",
+	"FY%F Q%Q",
+	"FY%f Q%Q",
+	"FY%FQ%Q",
+	"FY%fQ%Q",
+}
+
+// mayBeFiscal reports whether s might match one of the quarter
+// or fiscal year layouts in anyFormats, all of which contain "Q"
+// or start with "FY".
+func mayBeFiscal(s string) bool {
+	return strings.Contains(s, "Q") || strings.HasPrefix(s, "FY")
+}
+
+// AnyLayouts returns the layouts tried by the any format, in order.
+func AnyLayouts() []string {
+	return append([]string(nil), anyFormats...)
+}
+
+// anyLayouts returns the layouts tried by p in the any format.
+func (p *Parser) anyLayouts() []string {
+	if len(p.AnyLayouts) == 0 {
+		return anyFormats
+	}
+	return append(append([]string(nil), p.AnyLayouts...), anyFormats...)
+}
+
+var unixFormats = []string{"unixnano", "unixmicro", "unixmilli", "unix"}
+
+func (p *Parser) parseAny(s string, tz *time.Location, now time.Time) (time.Time, string, error) {
+	if p.Serial != "" && isSerialCandidate(s) {
+		t, err := parseSerial(serialSystems[p.Serial], s, tz)
+		return t, p.Serial, err
+	}
+	if isIXDTF(s) {
+		t, err := p.parseIXDTF(s, tz)
+		return t, "ixdtf", err
+	}
+	for _, format := range anyIDFormats {
+		if f := idFormats[format]; f.pattern.MatchString(s) {
+			if t, err := f.parse(s); err == nil {
+				return t.In(tz), format, nil
+			}
+		}
+	}
+	if numberPattern.MatchString(s) {
+		for _, format := range unixFormats {
+			t, _, err := p.parseCustom(format, s, tz, now)
+			if err != nil {
+				continue
+			}
+			if format == "unix" {
+				return t, format, nil
+			}
+			tooSmall := t.Year() == 1970 && t.Month() == time.January ||
+				t.Year() == 1969 && t.Month() != time.December
+			if !tooSmall {
+				return t, format, nil
+			}
+		}
+	}
+	fiscal := mayBeFiscal(s)
+	for i, format := range p.anyLayouts() {
+		if i >= len(p.AnyLayouts) && !fiscal && strings.Contains(format, "%") {
+			// Parsing with extension elements is slow,
+			// so don't try the built-in quarter and fiscal
+			// year layouts when they can't match.
+			continue
+		}
+		t, err := p.Style.ParseInLocation(format, s, tz)
+		if err != nil {
+			continue
+		}
+		if p.Abs {
+			return t, format, nil
+		}
+		return relativeTime(t, timeformat.LayoutComponents(format), now), format, nil
+	}
+	return time.Time{}, "", fmt.Errorf("cannot parse %q as arbitrary format", s)
+}
+
+// Format returns t formatted in the given format, as accepted by
+// LookupFormat. Layouts are formatted with the given style.
+// The any format prints times in RFC3339 format.
+func Format(t time.Time, format string, style timeformat.Style) (string, error) {
+	layout, custom, err := LookupFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if !custom {
+		return style.Format(t, layout)
+	}
+	if sys, ok := serialSystems[layout]; ok {
+		return formatSerial(sys, t), nil
+	}
+	if layout == "any" {
+		// Arbitrary.
+		return t.Format(time.RFC3339), nil
+	}
+	if layout == "ixdtf" {
+		return formatIXDTF(t), nil
+	}
+	if f, _ := lookupIDFormat(layout); f != nil {
+		return f.format(t)
+	}
+	f, _ := lookupEpochFormat(layout)
+	if f == nil {
+		panic("unknown custom time format")
+	}
+	return f.format(t), nil
+}
+
+// IsNumeric reports whether the given format, as accepted by
+// LookupFormat, prints times as decimal numbers. That's true
+// of the epoch formats, except for ntp64, which is printed in
+// hexadecimal, and of the spreadsheet serial date formats.
+func IsNumeric(format string) bool {
+	layout, custom, err := LookupFormat(format)
+	if err != nil || !custom {
+		return false
+	}
+	if _, ok := serialSystems[layout]; ok {
+		return true
+	}
+	f, _ := lookupEpochFormat(layout)
+	return f != nil && !f.fixed
+}
@@ -0,0 +1,311 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/rogpeppe/godate/timeformat"
+)
+
+// now is used as the current time by the tests.
+var now = time.Date(2024, 5, 15, 13, 47, 12, 0, time.UTC)
+
+var parseTests = []struct {
+	testName     string
+	parser       Parser
+	s            string
+	expect       time.Time
+	expectFormat string
+}{{
+	testName:     "now",
+	s:            "now",
+	expect:       now,
+	expectFormat: "now",
+}, {
+	testName:     "any-rfc3339",
+	s:            "2024-01-02T03:04:05+01:00",
+	expect:       time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC),
+	expectFormat: time.RFC3339,
+}, {
+	testName:     "any-relative",
+	s:            "10:30",
+	expect:       time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC),
+	expectFormat: "15:04",
+}, {
+	testName:     "any-abs",
+	parser:       Parser{Abs: true},
+	s:            "10:30",
+	expect:       time.Date(0, 1, 1, 10, 30, 0, 0, time.UTC),
+	expectFormat: "15:04",
+}, {
+	testName:     "any-unix",
+	s:            "1700000000",
+	expect:       time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+	expectFormat: "unix",
+}, {
+	testName:     "any-unixmilli",
+	s:            "1700000000123",
+	expect:       time.Date(2023, 11, 14, 22, 13, 20, 123e6, time.UTC),
+	expectFormat: "unixmilli",
+}, {
+	testName:     "any-month-day",
+	s:            "Mar 15",
+	expect:       time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	expectFormat: "Jan 2",
+}, {
+	testName:     "any-month-day-time",
+	s:            "Mar 3 10:00",
+	expect:       time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC),
+	expectFormat: "Jan 2 15:04",
+}, {
+	testName:     "any-day-month",
+	s:            "15 Mar 10:00:30",
+	expect:       time.Date(2024, 3, 15, 10, 0, 30, 0, time.UTC),
+	expectFormat: "2 Jan 15:04:05",
+}, {
+	testName:     "any-serial",
+	parser:       Parser{Serial: "excel"},
+	s:            "45292",
+	expect:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "excel",
+}, {
+	testName:     "any-layouts",
+	parser:       Parser{AnyLayouts: []string{"02/01/2006"}},
+	s:            "03/02/2024",
+	expect:       time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC),
+	expectFormat: "02/01/2006",
+}, {
+	testName:     "any-quarter",
+	s:            "2024Q3",
+	expect:       time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "2006Q%Q",
+}, {
+	testName:     "any-fiscal-year",
+	s:            "FY2025 Q2",
+	expect:       time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "FY%F Q%Q",
+}, {
+	testName:     "any-layouts-extension",
+	parser:       Parser{AnyLayouts: []string{"day %j of 2006"}},
+	s:            "day 032 of 2024",
+	expect:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "day %j of 2006",
+}, {
+	testName:     "named-format",
+	parser:       Parser{Format: "RFC1123"},
+	s:            "Tue, 02 Jan 2024 03:04:05 UTC",
+	expect:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	expectFormat: "RFC1123",
+}, {
+	testName:     "layout-relative",
+	parser:       Parser{Format: "Jan 2"},
+	s:            "Mar 4",
+	expect:       time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+	expectFormat: "Jan 2",
+}, {
+	testName:     "location",
+	parser:       Parser{Format: "2006-01-02 15:04", Location: time.FixedZone("X", 3600)},
+	s:            "2024-01-02 03:04",
+	expect:       time.Date(2024, 1, 2, 2, 4, 0, 0, time.UTC),
+	expectFormat: "2006-01-02 15:04",
+}, {
+	testName:     "custom",
+	parser:       Parser{Format: "unixmilli"},
+	s:            "1700000000123",
+	expect:       time.Date(2023, 11, 14, 22, 13, 20, 123e6, time.UTC),
+	expectFormat: "unixmilli",
+}}
+
+func TestParse(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := test.parser
+			p.Now = now
+			if p.Location == nil {
+				p.Location = time.UTC
+			}
+			got, format, err := p.Parse(test.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got.Equal(test.expect), qt.IsTrue, qt.Commentf("got %v", got))
+			c.Assert(format, qt.Equals, test.expectFormat)
+		})
+	}
+}
+
+func TestParseChecked(t *testing.T) {
+	c := qt.New(t)
+	p := Parser{Format: "epoch:2020-01-01/1h", Location: time.UTC}
+	c.Assert(p.Check(), qt.IsNil)
+	got, format, err := p.Parse("25")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.Equals, time.Date(2020, 1, 2, 1, 0, 0, 0, time.UTC))
+	c.Assert(format, qt.Equals, "epoch:2020-01-01/1h")
+
+	// Changing the format after Check is still respected.
+	p.Format = "unix"
+	got, format, err = p.Parse("1700000000")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.Equals, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC))
+	c.Assert(format, qt.Equals, "unix")
+
+	p.Format = "epoch:yesterday"
+	_, _, err = p.Parse("1")
+	c.Assert(err, qt.ErrorMatches, `invalid epoch "yesterday" in "epoch:yesterday"`)
+	c.Assert(p.Check(), qt.ErrorMatches, `invalid epoch "yesterday" in "epoch:yesterday"`)
+
+	p = Parser{Serial: "visicalc"}
+	_, _, err = p.Parse("1")
+	c.Assert(err, qt.ErrorMatches, `unknown spreadsheet date system "visicalc"`)
+}
+
+func TestParseInlineZones(t *testing.T) {
+	c := qt.New(t)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	c.Assert(err, qt.IsNil)
+	p := Parser{
+		Location:    time.UTC,
+		Now:         now,
+		InlineZones: true,
+	}
+	tm, format, err := p.Parse("09:00@tokyo")
+	c.Assert(err, qt.IsNil)
+	c.Assert(tm, qt.DeepEquals, time.Date(2024, 5, 15, 9, 0, 0, 0, tokyo))
+	c.Assert(format, qt.Equals, "15:04")
+
+	tm, _, err = p.Parse("2024-03-01 10:00 Europe/Paris")
+	c.Assert(err, qt.IsNil)
+	c.Assert(tm.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)), qt.IsTrue)
+	c.Assert(tm.Location().String(), qt.Equals, "Europe/Paris")
+
+	tm, _, err = p.Parse("now@tokyo")
+	c.Assert(err, qt.IsNil)
+	c.Assert(tm, qt.DeepEquals, now.In(tokyo))
+
+	_, _, err = p.Parse("09:00@nowhere")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "09:00@nowhere" as arbitrary format \(unknown time zone nowhere\)`)
+	_, _, err = p.Parse("09:00 nowhere")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "09:00 nowhere" as arbitrary format`)
+
+	p.InlineZones = false
+	_, _, err = p.Parse("09:00@tokyo")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "09:00@tokyo" as arbitrary format`)
+
+	p = Parser{
+		InlineZones: true,
+		LoadLocation: func(name string) (*time.Location, error) {
+			return time.FixedZone(name, 3600), nil
+		},
+	}
+	tm, _, err = p.Parse("2024-01-01@office")
+	c.Assert(err, qt.IsNil)
+	c.Assert(tm.Location().String(), qt.Equals, "office")
+}
+
+func TestParseErrors(t *testing.T) {
+	c := qt.New(t)
+	p := Parser{Location: time.UTC}
+	_, _, err := p.Parse("not a time")
+	c.Assert(err, qt.ErrorMatches, `cannot parse "not a time" as arbitrary format`)
+	p = Parser{Serial: "abacus"}
+	c.Assert(p.Check(), qt.ErrorMatches, `unknown spreadsheet date system "abacus"`)
+	_, _, err = p.Parse("1")
+	c.Assert(err, qt.ErrorMatches, `unknown spreadsheet date system "abacus"`)
+}
+
+func TestFormat(t *testing.T) {
+	c := qt.New(t)
+	t0 := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	for format, expect := range map[string]string{
+		"unix":       "1700000000",
+		"rfc3339":    "2023-11-14T22:13:20Z",
+		"2006-01-02": "2023-11-14",
+		"any":        "2023-11-14T22:13:20Z",
+	} {
+		got, err := Format(t0, format, timeformat.Style{})
+		c.Assert(err, qt.IsNil)
+		c.Check(got, qt.Equals, expect, qt.Commentf("%s", format))
+	}
+}
+
+func TestIsNumeric(t *testing.T) {
+	c := qt.New(t)
+	for format, expect := range map[string]bool{
+		"unix":                true,
+		"UnixMilli":           true,
+		"unixfloat:3":         true,
+		"epoch:2020-01-01/1h": true,
+		"gps":                 true,
+		"excel":               true,
+		"ntp64":               false,
+		"discord":             false,
+		"ulid":                false,
+		"any":                 false,
+		"0102":                false,
+		"20060102":            false,
+		"rfc3339":             false,
+	} {
+		c.Check(IsNumeric(format), qt.Equals, expect, qt.Commentf("%s", format))
+	}
+}
+
+func TestPattern(t *testing.T) {
+	c := qt.New(t)
+	p := Parser{}
+	pat, err := p.Pattern()
+	c.Assert(err, qt.IsNil)
+	line := "started 2024-01-02T03:04:05Z and ended at 1700000000 after 2024"
+	c.Assert(pat.FindAllString(line, -1), qt.DeepEquals, []string{"2024-01-02T03:04:05Z", "1700000000"})
+	p = Parser{Format: "ulid"}
+	pat, err = p.Pattern()
+	c.Assert(err, qt.IsNil)
+	c.Assert(pat.MatchString("01HF7Y5Y0000000000000000000"), qt.IsTrue)
+}
+
+var epochPatternTests = []struct {
+	format string
+	line   string
+	expect []string
+}{{
+	format: "unixmilli",
+	line:   "status 200 at 2024-01-02T03:04:05Z took 1700000000000.5 ms",
+	expect: []string{"1700000000000.5"},
+}, {
+	format: "unix",
+	line:   "1700000000 and 17000000",
+	expect: []string{"1700000000"},
+}, {
+	format: "unixnano",
+	line:   "1700000000123456789",
+	expect: []string{"1700000000123456789"},
+}, {
+	format: "excel",
+	line:   "45292.5 in row 12",
+	expect: []string{"45292.5"},
+}, {
+	format: "ntp64",
+	line:   "e90a4a7f.80000000 and 0xe90a4a7f80000000",
+	expect: []string{"e90a4a7f.80000000", "0xe90a4a7f80000000"},
+}, {
+	format: "discord",
+	line:   "user 42 sent 175928847299117063",
+	expect: []string{"175928847299117063"},
+}, {
+	// Counts since an epoch within the plausible range
+	// may be negative and short.
+	format: "epoch:2020-01-01/1h",
+	line:   "-8760 and 42",
+	expect: []string{"-8760", "42"},
+}}
+
+func TestEpochPattern(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range epochPatternTests {
+		p := Parser{Format: test.format}
+		pat, err := p.Pattern()
+		c.Assert(err, qt.IsNil)
+		c.Check(pat.FindAllString(test.line, -1), qt.DeepEquals, test.expect, qt.Commentf("%s", test.format))
+	}
+}
@@ -0,0 +1,158 @@
+package timeparse
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// anyNumberPattern matches numbers that the any format
+// might plausibly interpret as Unix times when they're
+// found within other text: between 10 digits (seconds
+// since late 2001) and 19 digits (nanoseconds).
+const anyNumberPattern = `[0-9]{10,19}(?:\.[0-9]+)?`
+
+// ntp64HexPattern matches the hexadecimal forms of 64-bit NTP
+// timestamps; the decimal form is matched like the other epoch formats.
+const ntp64HexPattern = `[0-9a-fA-F]{8}\.[0-9a-fA-F]{1,8}|0x[0-9a-fA-F]{16}`
+
+// snowflakePattern matches snowflake identifiers made more than
+// a day or so after their epoch.
+const snowflakePattern = `[0-9]{15,20}`
+
+// The numbers found within other text in the epoch and serial
+// formats are taken to be times between plausibleStart and
+// plausibleEnd, the times that have 10-digit Unix times,
+// as anyNumberPattern does.
+var (
+	plausibleStart = time.Unix(1e9, 0)
+	plausibleEnd   = time.Unix(1e10-1, 0)
+)
+
+// Pattern returns an unanchored regular expression that matches text
+// that might be parsed as a time by p, which is useful for finding
+// times within other text. In the any format, it doesn't match bare
+// years and short numbers, which are more likely not to be times.
+func (p *Parser) Pattern() (*regexp.Regexp, error) {
+	format, custom, err := LookupFormat(p.format())
+	if err != nil {
+		return nil, err
+	}
+	if !custom {
+		return regexp.Compile(p.Style.LayoutPattern(format))
+	}
+	if format == "any" {
+		return regexp.Compile(p.anyPattern())
+	}
+	if format == "ixdtf" {
+		return regexp.Compile(unanchored(ixdtfPattern))
+	}
+	if f, _ := lookupIDFormat(format); f != nil {
+		if f.pattern == nil {
+			return regexp.Compile(snowflakePattern)
+		}
+		return regexp.Compile(unanchored(f.pattern))
+	}
+	if sys, ok := serialSystems[format]; ok {
+		return regexp.Compile(sys.pattern())
+	}
+	f, err := lookupEpochFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("no pattern for format %q", format)
+	}
+	return regexp.Compile(f.pattern())
+}
+
+// pattern returns a pattern that matches the counts in f of
+// times between plausibleStart and plausibleEnd.
+func (f *epochFormat) pattern() string {
+	count := func(t time.Time) *big.Int {
+		v := new(big.Rat).SetInt64(t.Unix() - f.epoch.Unix())
+		v.Quo(v, f.unit)
+		return new(big.Int).Quo(v.Num(), v.Denom())
+	}
+	pat := countPattern(count(plausibleStart), count(plausibleEnd))
+	if f.fixed {
+		return ntp64HexPattern + "|" + pat
+	}
+	return pat
+}
+
+// pattern returns a pattern that matches the serial dates in sys
+// of times between plausibleStart and plausibleEnd.
+func (sys serialSystem) pattern() string {
+	count := func(t time.Time) *big.Int {
+		return big.NewInt((t.Unix() - sys.epoch.Unix()) / (24 * 60 * 60))
+	}
+	return countPattern(count(plausibleStart), count(plausibleEnd))
+}
+
+// countPattern returns a pattern that matches decimal numbers,
+// possibly with a fractional part, whose integer parts have
+// as many digits as the counts between lo and hi.
+func countPattern(lo, hi *big.Int) string {
+	minDigits := len(new(big.Int).Abs(lo).String())
+	maxDigits := len(new(big.Int).Abs(hi).String())
+	if minDigits > maxDigits {
+		minDigits, maxDigits = maxDigits, minDigits
+	}
+	sign := ""
+	switch {
+	case lo.Sign() < 0 && hi.Sign() < 0:
+		sign = "-"
+	case lo.Sign() < 0:
+		// The range crosses the epoch.
+		sign, minDigits = "-?", 1
+	}
+	digits := fmt.Sprint(minDigits)
+	if maxDigits > minDigits {
+		digits += fmt.Sprint(",", maxDigits)
+	}
+	return sign + `[0-9]{` + digits + `}(?:\.[0-9]+)?`
+}
+
+// anyPattern returns a pattern that matches any of the times
+// recognized by the any format, except for bare years
+// and short numbers.
+func (p *Parser) anyPattern() string {
+	var layouts []string
+	for _, layout := range p.anyLayouts() {
+		if layout != "2006" {
+			layouts = append(layouts, layout)
+		}
+	}
+	// Regular expression alternation prefers earlier alternatives,
+	// so try the longer and more specific layouts first.
+	sort.SliceStable(layouts, func(i, j int) bool {
+		return len(layouts[i]) > len(layouts[j])
+	})
+	pats := []string{ixdtfAnyPattern}
+	for _, format := range anyIDFormats {
+		pats = append(pats, unanchored(idFormats[format].pattern))
+	}
+	for _, layout := range layouts {
+		pats = append(pats, p.Style.LayoutPattern(layout))
+	}
+	pats = append(pats, anyNumberPattern)
+	return "(?:" + strings.Join(pats, ")|(?:") + ")"
+}
+
+// unanchored returns the source of the given anchored
+// regular expression without its anchors.
+func unanchored(re *regexp.Regexp) string {
+	s := re.String()
+	flags := ""
+	if strings.HasPrefix(s, "^(?i)") {
+		flags = "(?i)"
+		s = strings.TrimPrefix(s, "^(?i)")
+	}
+	s = strings.TrimPrefix(s, "^")
+	s = strings.TrimSuffix(s, "$")
+	return "(?:" + flags + s + ")"
+}
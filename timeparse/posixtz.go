@@ -0,0 +1,243 @@
+package timeparse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixedZonePattern matches a fixed UTC offset such as "+05:30",
+// "UTC-3" or "GMT+8". The submatches are the sign, the hours
+// and the minutes.
+var fixedZonePattern = regexp.MustCompile(`^(?i:utc|gmt)?([-+])([0-9]{1,2})(?::?([0-9]{2}))?$`)
+
+// fixedZone returns the time zone for the fixed UTC offset name,
+// and reports whether name is a fixed offset. Positive offsets
+// are east of UTC, as in RFC 3339, which is the opposite of
+// the Etc/GMT zones and POSIX TZ strings.
+func fixedZone(name string) (*time.Location, bool) {
+	m := fixedZonePattern.FindStringSubmatch(name)
+	if m == nil {
+		return nil, false
+	}
+	h, _ := strconv.Atoi(m[2])
+	min, _ := strconv.Atoi(m[3])
+	if h > 23 || min > 59 {
+		return nil, false
+	}
+	off := h*3600 + min*60
+	if m[1] == "-" {
+		off = -off
+	}
+	return time.FixedZone(formatOffset(off), off), true
+}
+
+// isPOSIXTZ reports whether name looks like a POSIX TZ
+// string, such as "EST5EDT,M3.2.0,M11.1.0", rather than
+// the name of a time zone.
+func isPOSIXTZ(name string) bool {
+	// Rules can contain slashes, but names can't.
+	zones := name
+	if i := strings.IndexByte(zones, ','); i >= 0 {
+		zones = zones[:i]
+	}
+	if zones == "" || strings.Contains(zones, "/") || !strings.ContainsAny(zones, "0123456789") {
+		return false
+	}
+	c := name[0]
+	return c == '<' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// loadPOSIXLocation returns a time zone that follows the rules
+// in the POSIX TZ string tz. As with glibc, daylight saving time
+// follows the US rules when tz names a daylight saving time
+// zone without saying when it starts and ends.
+func loadPOSIXLocation(tz string) (*time.Location, error) {
+	invalid := fmt.Errorf("invalid POSIX TZ string %q", tz)
+	std, s, ok := tzName(tz)
+	if !ok {
+		return nil, invalid
+	}
+	stdOffset, s, ok := tzOffset(s, 24)
+	if !ok {
+		return nil, invalid
+	}
+	extend := tz
+	if s != "" {
+		if _, s, ok = tzName(s); !ok {
+			return nil, invalid
+		}
+		if s != "" && s[0] != ',' {
+			if _, s, ok = tzOffset(s, 24); !ok {
+				return nil, invalid
+			}
+		}
+		if s == "" {
+			extend += ",M3.2.0,M11.1.0"
+		} else {
+			for i := 0; i < 2; i++ {
+				if s == "" || s[0] != ',' {
+					return nil, invalid
+				}
+				if s, ok = tzRule(s[1:]); !ok {
+					return nil, invalid
+				}
+			}
+			if s != "" {
+				return nil, invalid
+			}
+		}
+	}
+	// POSIX offsets are west of UTC.
+	return time.LoadLocationFromTZData(tz, tzData(std, -stdOffset, extend))
+}
+
+// tzData returns time zone data in the TZif format of RFC 8536 for
+// a zone with no transitions, so that its rules are entirely given by
+// the POSIX TZ string extend. The single local time type has the given
+// abbreviation and offset east of UTC.
+func tzData(abbrev string, offset int, extend string) []byte {
+	var b bytes.Buffer
+	// The data is written twice, with 32-bit and then 64-bit
+	// transition times, but as there are no transitions
+	// the two blocks are the same.
+	for i := 0; i < 2; i++ {
+		b.WriteString("TZif2")
+		b.Write(make([]byte, 15))
+		// The counts of UT/local indicators, standard/wall indicators,
+		// leap seconds, transitions, local time types and abbreviation
+		// bytes.
+		for _, n := range []int{0, 0, 0, 0, 1, len(abbrev) + 1} {
+			binary.Write(&b, binary.BigEndian, uint32(n))
+		}
+		binary.Write(&b, binary.BigEndian, int32(offset))
+		b.WriteByte(0) // Not daylight saving time.
+		b.WriteByte(0) // The index of the abbreviation.
+		b.WriteString(abbrev)
+		b.WriteByte(0)
+	}
+	b.WriteString("\n" + extend + "\n")
+	return b.Bytes()
+}
+
+// tzName parses a time zone abbreviation at the start of s, which is
+// either three or more letters or, in angle brackets, three or more
+// letters, digits and signs. It returns the abbreviation without any
+// brackets and the rest of s.
+func tzName(s string) (name, rest string, ok bool) {
+	if strings.HasPrefix(s, "<") {
+		end := strings.IndexByte(s, '>')
+		if end < 4 {
+			return "", "", false
+		}
+		name = s[1:end]
+		for _, c := range name {
+			if !isAlpha(c) && !('0' <= c && c <= '9') && c != '+' && c != '-' {
+				return "", "", false
+			}
+		}
+		return name, s[end+1:], true
+	}
+	i := 0
+	for i < len(s) && isAlpha(rune(s[i])) {
+		i++
+	}
+	if i < 3 {
+		return "", "", false
+	}
+	return s[:i], s[i:], true
+}
+
+func isAlpha(c rune) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// tzOffset parses an offset or time of day of the form
+// [+-]hh[:mm[:ss]] at the start of s, with no more than
+// maxHours hours. It returns the offset in seconds and
+// the rest of s.
+func tzOffset(s string, maxHours int) (secs int, rest string, ok bool) {
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	var n int
+	n, s, ok = tzNumber(s, 1, 3, maxHours)
+	if !ok {
+		return 0, "", false
+	}
+	secs = n * 3600
+	for _, mul := range []int{60, 1} {
+		if !strings.HasPrefix(s, ":") {
+			break
+		}
+		n, s, ok = tzNumber(s[1:], 2, 2, 59)
+		if !ok {
+			return 0, "", false
+		}
+		secs += n * mul
+	}
+	if neg {
+		secs = -secs
+	}
+	return secs, s, true
+}
+
+// tzRule parses a rule saying when daylight saving time starts or ends
+// at the start of s: a Julian day Jn (1 to 365, not counting February
+// 29th), a zero-based day of the year n (0 to 365), or Mm.w.d, the
+// day d (0 is Sunday) of week w (1 to 5, where 5 is the last) of month
+// m, optionally followed by / and the time of day, which may be
+// negative or more than 24 hours. It returns the rest of s.
+func tzRule(s string) (rest string, ok bool) {
+	switch {
+	case strings.HasPrefix(s, "J"):
+		var n int
+		n, s, ok = tzNumber(s[1:], 1, 3, 365)
+		ok = ok && n >= 1
+	case strings.HasPrefix(s, "M"):
+		s = s[1:]
+		var n int
+		for i, max := range []int{12, 5, 6} {
+			if i > 0 {
+				if !strings.HasPrefix(s, ".") {
+					return "", false
+				}
+				s = s[1:]
+			}
+			n, s, ok = tzNumber(s, 1, 2, max)
+			if !ok || n == 0 && i < 2 {
+				return "", false
+			}
+		}
+	default:
+		_, s, ok = tzNumber(s, 1, 3, 365)
+	}
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(s, "/") {
+		_, s, ok = tzOffset(s[1:], 167)
+	}
+	return s, ok
+}
+
+// tzNumber parses a decimal number of between minDigits and maxDigits
+// digits, no greater than max, at the start of s. It returns the
+// number and the rest of s.
+func tzNumber(s string, minDigits, maxDigits, max int) (n int, rest string, ok bool) {
+	i := 0
+	for i < len(s) && i < maxDigits && '0' <= s[i] && s[i] <= '9' {
+		n = n*10 + int(s[i]-'0')
+		i++
+	}
+	if i < minDigits || n > max {
+		return 0, "", false
+	}
+	return n, s[i:], true
+}
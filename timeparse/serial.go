@@ -0,0 +1,136 @@
+package timeparse
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serialSystem describes a spreadsheet date system, where a time is
+// held as a count of days (with a fractional part for the time of day)
+// since some epoch.
+type serialSystem struct {
+	// epoch holds the date of day zero.
+	epoch time.Time
+	// leapBug is true when the system pretends that 1900 was a leap
+	// year, as Lotus 1-2-3 did and Excel still does for compatibility.
+	leapBug bool
+	// negative is true when the system allows dates before the epoch.
+	negative bool
+}
+
+// serialSystems holds all the known spreadsheet date systems, keyed by
+// format name.
+var serialSystems = map[string]serialSystem{
+	"excel": {
+		epoch:   time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC),
+		leapBug: true,
+	},
+	"lotus": {
+		epoch:   time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC),
+		leapBug: true,
+	},
+	"excel1904": {
+		epoch: time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC),
+	},
+	"libreoffice": {
+		epoch:    time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC),
+		negative: true,
+	},
+}
+
+// SerialSystems returns the names of the known spreadsheet
+// date systems in alphabetical order.
+func SerialSystems() []string {
+	names := make([]string, 0, len(serialSystems))
+	for name := range serialSystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// leapBugEnd holds the first day that the 1900 leap year
+// bug no longer affects (serial 61 in the Excel 1900 date system).
+var leapBugEnd = time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+var serialPattern = regexp.MustCompile(`^-?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// parseSerial parses s as a serial date in the given system,
+// interpreting it as a wall clock time in the given location.
+// The time of day is rounded to the nearest millisecond,
+// which is the resolution that spreadsheets use.
+func parseSerial(sys serialSystem, s string, tz *time.Location) (time.Time, error) {
+	if !serialPattern.MatchString(s) {
+		return time.Time{}, fmt.Errorf("invalid serial date %q", s)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid serial date %q: %v", s, err)
+	}
+	if v < 0 && !sys.negative {
+		return time.Time{}, fmt.Errorf("serial date %q out of range", s)
+	}
+	days := math.Floor(v)
+	if math.Abs(days) > 1e7 {
+		return time.Time{}, fmt.Errorf("serial date %q out of range", s)
+	}
+	ms := math.Round((v - days) * 24 * 60 * 60 * 1000)
+	if sys.leapBug && days <= 60 {
+		// Before the non-existent leap day, the epoch is effectively
+		// one day later. Day 60 is the leap day itself, which
+		// spreadsheets do produce, so it's read as the day after
+		// 28 February, 1 March 1900, the same as day 61.
+		days++
+	}
+	e := sys.epoch
+	return time.Date(e.Year(), e.Month(), e.Day()+int(days), 0, 0, 0, int(ms)*int(time.Millisecond), tz), nil
+}
+
+// formatSerial returns t as a serial date in the given system,
+// using the wall clock time in t's location.
+// Like spreadsheets, it prints up to 15 significant digits.
+func formatSerial(sys serialSystem, t time.Time) string {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := (date.Unix() - sys.epoch.Unix()) / (24 * 60 * 60)
+	if sys.leapBug && date.Before(leapBugEnd) {
+		days--
+	}
+	clock := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+	if clock == 0 {
+		return strconv.FormatInt(days, 10)
+	}
+	v := float64(days) + float64(clock)/float64(24*time.Hour)
+	prec := 15 - len(strconv.FormatInt(days, 10))
+	if days < 0 {
+		prec++
+	}
+	if prec < 0 {
+		prec = 0
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// isSerialCandidate reports whether s looks like a spreadsheet serial date
+// rather than a Unix time: a number with no more than five integer digits.
+func isSerialCandidate(s string) bool {
+	if !serialPattern.MatchString(s) || strings.HasPrefix(s, "-") {
+		return false
+	}
+	if i := strings.Index(s, "."); i >= 0 {
+		s = s[:i]
+	}
+	return len(s) <= 5
+}
@@ -0,0 +1,133 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var serialTests = []struct {
+	testName string
+	system   string
+	s        string
+	expect   time.Time
+	// expectFormat holds the formatted time,
+	// if it's different from s.
+	expectFormat string
+}{{
+	testName: "excel-day-1",
+	system:   "excel",
+	s:        "1",
+	expect:   time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel-day-59",
+	system:   "excel",
+	s:        "59",
+	expect:   time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC),
+}, {
+	testName:     "excel-leap-day",
+	system:       "excel",
+	s:            "60",
+	expect:       time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC),
+	expectFormat: "61",
+}, {
+	testName:     "excel-leap-day-fraction",
+	system:       "excel",
+	s:            "60.5",
+	expect:       time.Date(1900, 3, 1, 12, 0, 0, 0, time.UTC),
+	expectFormat: "61.5",
+}, {
+	testName: "excel-day-61",
+	system:   "excel",
+	s:        "61",
+	expect:   time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel-fraction",
+	system:   "excel",
+	s:        "45292.5",
+	expect:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel-fraction-before-leap-day",
+	system:   "excel",
+	s:        "59.25",
+	expect:   time.Date(1900, 2, 28, 6, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel-milliseconds",
+	system:   "excel",
+	s:        "45292.000011574",
+	expect:   time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+	// 1s is 0.0000115740740740741 of a day.
+	expectFormat: "45292.0000115741",
+}, {
+	testName: "lotus-day-1",
+	system:   "lotus",
+	s:        "1",
+	expect:   time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel1904-day-0",
+	system:   "excel1904",
+	s:        "0",
+	expect:   time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "excel1904-fraction",
+	system:   "excel1904",
+	s:        "43830.75",
+	expect:   time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC),
+}, {
+	testName: "libreoffice-day-60",
+	system:   "libreoffice",
+	s:        "60",
+	expect:   time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC),
+}, {
+	testName: "libreoffice-negative",
+	system:   "libreoffice",
+	s:        "-1.5",
+	// Day -2 plus half a day.
+	expect: time.Date(1899, 12, 28, 12, 0, 0, 0, time.UTC),
+}}
+
+func TestSerial(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range serialTests {
+		c.Run(test.testName, func(c *qt.C) {
+			sys := serialSystems[test.system]
+			got, err := parseSerial(sys, test.s, time.UTC)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, test.expect)
+			expectFormat := test.expectFormat
+			if expectFormat == "" {
+				expectFormat = test.s
+			}
+			c.Assert(formatSerial(sys, got), qt.Equals, expectFormat)
+		})
+	}
+}
+
+func TestSerialErrors(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range []struct {
+		system      string
+		s           string
+		expectError string
+	}{{
+		system:      "excel",
+		s:           "-1",
+		expectError: `serial date "-1" out of range`,
+	}, {
+		system:      "excel1904",
+		s:           "-0.5",
+		expectError: `serial date "-0.5" out of range`,
+	}, {
+		system:      "excel",
+		s:           "1e5",
+		expectError: `invalid serial date "1e5"`,
+	}, {
+		system:      "excel",
+		s:           "100000000",
+		expectError: `serial date "100000000" out of range`,
+	}} {
+		_, err := parseSerial(serialSystems[test.system], test.s, time.UTC)
+		c.Check(err, qt.ErrorMatches, test.expectError, qt.Commentf("%s %s", test.system, test.s))
+	}
+}
@@ -0,0 +1,137 @@
+package timeparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:generate bash getzones.bash
+
+// LoadLocation returns the time zone with the given name. As well
+// as the names accepted by time.LoadLocation, it accepts "local" and
+// "utc" in any case, and any unambiguous match as returned
+// by MatchZones, so "tokyo" means Asia/Tokyo. It's also OK
+// for the name to match several zones as long as they're all
+// links to the same zone.
+//
+// The name may also be a fixed offset east of UTC, optionally
+// preceded by UTC or GMT, such as "+05:30", "UTC-3" or "GMT+8",
+// or a POSIX TZ string such as "EST5EDT,M3.2.0,M11.1.0", whose
+// offsets are west of UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	switch strings.ToLower(name) {
+	case "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	if tz, ok := fixedZone(name); ok {
+		return tz, nil
+	}
+	tz, err := time.LoadLocation(name)
+	if err == nil {
+		return tz, nil
+	}
+	if isPOSIXTZ(name) {
+		return loadPOSIXLocation(name)
+	}
+	available := MatchZones(name)
+	if len(available) > 1 {
+		// If the zones are actually all referring to the same underlying time zone, then
+		// allow it (for example, "samoa" could match both "US/Samoa" and "Pacific/Samoa"
+		// but they're actually both the same)
+		if !allIdenticalZones(available) {
+			return nil, &AmbiguousZoneError{
+				Name:    name,
+				Matches: available,
+			}
+		}
+	}
+	if len(available) == 0 {
+		return nil, err
+	}
+	tz, err = time.LoadLocation(available[0])
+	if err != nil {
+		return nil, fmt.Errorf("time zone %s not available in system time zone database: %v", available[0], err)
+	}
+	return tz, nil
+}
+
+// AmbiguousZoneError is returned by LoadLocation when
+// a name matches more than one time zone.
+type AmbiguousZoneError struct {
+	Name    string
+	Matches []string
+}
+
+func (e *AmbiguousZoneError) Error() string {
+	return fmt.Sprintf("ambiguous time zone %q (%d matches)", e.Name, len(e.Matches))
+}
+
+// MatchZones returns the names of the time zones in the IANA time zone
+// database that match name, in no particular order. A zone matches
+// if its name is the same as name, ignoring case, or if there are no
+// such zones, if its name contains name, ignoring case.
+func MatchZones(name string) []string {
+	if _, ok := zoneNames[name]; ok {
+		return []string{name}
+	}
+	var matches []string
+	for zone := range zoneNames {
+		if strings.EqualFold(zone, name) {
+			matches = append(matches, zone)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+	name = strings.ToLower(name)
+	for zone := range zoneNames {
+		if strings.Contains(strings.ToLower(zone), name) {
+			matches = append(matches, zone)
+		}
+	}
+	return matches
+}
+
+// ZoneLink returns the name of the zone that the named zone
+// is a link to, or the empty string if it's not a link.
+func ZoneLink(name string) string {
+	return zoneNames[name]
+}
+
+// ZoneName returns the name of the time zone location loc. Unlike
+// loc.String, it returns the name of the local time zone, such as
+// "Europe/London", for time.Local when that name is known.
+func ZoneName(loc *time.Location) string {
+	if loc == time.Local {
+		if name := localZoneName(); name != "" {
+			return name
+		}
+	}
+	return loc.String()
+}
+
+func allIdenticalZones(tzs []string) bool {
+	if len(tzs) < 2 {
+		return true
+	}
+	ctz := canonicalTimezone(tzs[0])
+	for _, tz := range tzs[1:] {
+		if canonicalTimezone(tz) != ctz {
+			return false
+		}
+	}
+	return true
+}
+
+func canonicalTimezone(tz string) string {
+	for {
+		link := zoneNames[tz]
+		if link == "" {
+			return tz
+		}
+		tz = link
+	}
+}
@@ -0,0 +1,162 @@
+package timeparse
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMatchZones(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(MatchZones("Europe/London"), qt.DeepEquals, []string{"Europe/London"})
+	c.Assert(MatchZones("europe/london"), qt.DeepEquals, []string{"Europe/London"})
+	c.Assert(MatchZones("tokyo"), qt.DeepEquals, []string{"Asia/Tokyo"})
+	zones := MatchZones("samoa")
+	sort.Strings(zones)
+	c.Assert(zones, qt.DeepEquals, []string{"Pacific/Samoa", "US/Samoa"})
+	c.Assert(MatchZones("nowhere"), qt.HasLen, 0)
+}
+
+func TestZoneLink(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(ZoneLink("US/Samoa"), qt.Equals, "Pacific/Pago_Pago")
+	c.Assert(ZoneLink("Europe/London"), qt.Equals, "")
+}
+
+func TestZoneName(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv("TZ", "Asia/Tokyo")
+	c.Assert(ZoneName(time.Local), qt.Equals, "Asia/Tokyo")
+	c.Setenv("TZ", "XYZ-3")
+	c.Assert(ZoneName(time.Local), qt.Equals, "Local")
+	c.Assert(ZoneName(time.UTC), qt.Equals, "UTC")
+	c.Assert(ZoneName(time.FixedZone("X", 3600)), qt.Equals, "X")
+}
+
+func TestLoadLocation(t *testing.T) {
+	c := qt.New(t)
+	loc, err := LoadLocation("UTC")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loc.String(), qt.Equals, "UTC")
+	loc, err = LoadLocation("tokyo")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loc.String(), qt.Equals, "Asia/Tokyo")
+	// Both matches are links to the same zone.
+	loc, err = LoadLocation("samoa")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loc.String(), qt.Matches, "(Pacific|US)/Samoa")
+	_, err = LoadLocation("america")
+	c.Assert(err, qt.ErrorMatches, `ambiguous time zone "america" \([0-9]+ matches\)`)
+	zerr, ok := err.(*AmbiguousZoneError)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(zerr.Name, qt.Equals, "america")
+}
+
+func TestLoadLocationFixedOffset(t *testing.T) {
+	c := qt.New(t)
+	t0 := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	for name, expect := range map[string]int{
+		"+05:30":    5*3600 + 1800,
+		"+0530":     5*3600 + 1800,
+		"-7":        -7 * 3600,
+		"UTC-3":     -3 * 3600,
+		"utc+5:45":  5*3600 + 45*60,
+		"GMT+8":     8 * 3600,
+		"Etc/GMT+8": -8 * 3600,
+	} {
+		loc, err := LoadLocation(name)
+		c.Assert(err, qt.IsNil, qt.Commentf("%s", name))
+		_, offset := t0.In(loc).Zone()
+		c.Check(offset, qt.Equals, expect, qt.Commentf("%s", name))
+	}
+	loc, err := LoadLocation("UTC-3")
+	c.Assert(err, qt.IsNil)
+	c.Assert(loc.String(), qt.Equals, "-03:00")
+}
+
+var posixTZTests = []struct {
+	tz           string
+	t            time.Time
+	expectAbbrev string
+	expectOffset int
+}{{
+	tz:           "EST5EDT,M3.2.0,M11.1.0",
+	t:            time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+	expectAbbrev: "EST",
+	expectOffset: -5 * 3600,
+}, {
+	tz:           "EST5EDT,M3.2.0,M11.1.0",
+	t:            time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC),
+	expectAbbrev: "EDT",
+	expectOffset: -4 * 3600,
+}, {
+	// Daylight saving time defaults to the US rules.
+	tz:           "EST5EDT",
+	t:            time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC),
+	expectAbbrev: "EDT",
+	expectOffset: -4 * 3600,
+}, {
+	tz:           "CET-1CEST,M3.5.0,M10.5.0/3",
+	t:            time.Date(2024, 3, 31, 0, 59, 59, 0, time.UTC),
+	expectAbbrev: "CET",
+	expectOffset: 3600,
+}, {
+	tz:           "CET-1CEST,M3.5.0,M10.5.0/3",
+	t:            time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC),
+	expectAbbrev: "CEST",
+	expectOffset: 2 * 3600,
+}, {
+	tz:           "CET-1CEST,M3.5.0,M10.5.0/3",
+	t:            time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC),
+	expectAbbrev: "CET",
+	expectOffset: 3600,
+}, {
+	// Southern hemisphere.
+	tz:           "<+1030>-10:30<+11>-11,M10.1.0,M4.1.0",
+	t:            time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	expectAbbrev: "+11",
+	expectOffset: 11 * 3600,
+}, {
+	tz:           "IST-5:30",
+	t:            time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	expectAbbrev: "IST",
+	expectOffset: 5*3600 + 1800,
+}, {
+	tz:           "XXX3YYY,J60/-1,300/26",
+	t:            time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	expectAbbrev: "YYY",
+	expectOffset: -2 * 3600,
+}}
+
+func TestLoadLocationPOSIXTZ(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range posixTZTests {
+		loc, err := LoadLocation(test.tz)
+		c.Assert(err, qt.IsNil, qt.Commentf("%s", test.tz))
+		c.Check(loc.String(), qt.Equals, test.tz)
+		abbrev, offset := test.t.In(loc).Zone()
+		c.Check(abbrev, qt.Equals, test.expectAbbrev, qt.Commentf("%s at %v", test.tz, test.t))
+		c.Check(offset, qt.Equals, test.expectOffset, qt.Commentf("%s at %v", test.tz, test.t))
+	}
+}
+
+func TestLoadLocationPOSIXTZError(t *testing.T) {
+	c := qt.New(t)
+	for _, tz := range []string{
+		"XX5",
+		"ABC25",
+		"EST5EDT,M3.2.0",
+		"EST5EDT,M13.2.0,M11.1.0",
+		"EST5EDT,M3.6.0,M11.1.0",
+		"EST5EDT,M3.2.7,M11.1.0",
+		"EST5EDT,J0,M11.1.0",
+		"EST5EDT,M3.2.0/168,M11.1.0",
+		"EST5EDT,M3.2.0,M11.1.0,",
+		"<AB>5",
+	} {
+		_, err := LoadLocation(tz)
+		c.Check(err, qt.ErrorMatches, `invalid POSIX TZ string ".*"`, qt.Commentf("%s", tz))
+	}
+}
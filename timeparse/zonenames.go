@@ -1,6 +1,6 @@
 // Code xx generated by getzones.bash. DO NOT EDIT.
 
-package main
+package timeparse
 
 var zoneNames = map[string]string{
 	"Africa/Algiers":                   "",
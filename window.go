@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/godate/timeparse"
+)
+
+// window selects the times in a range. The zero window
+// selects all times.
+type window struct {
+	// after holds the start of the window, inclusive.
+	// If it's zero, the window has no start.
+	after time.Time
+	// before holds the end of the window, exclusive.
+	// If it's zero, the window has no end.
+	before time.Time
+	// keep records whether the most recent record with
+	// a time was inside the window.
+	keep bool
+	// seen, if non-nil, is called with the time of
+	// each record found inside the window.
+	seen func(t time.Time)
+}
+
+// newWindow returns the window specified by the
+// -after, -before and -between flags.
+func newWindow() (*window, error) {
+	w := &window{}
+	after, before := *afterFlag, *beforeFlag
+	if *betweenFlag != "" {
+		if after != "" || before != "" {
+			return nil, fmt.Errorf("cannot use -between with -after or -before")
+		}
+		i := strings.Index(*betweenFlag, "..")
+		if i == -1 {
+			return nil, fmt.Errorf("-between value %q is not of the form start..end", *betweenFlag)
+		}
+		after, before = (*betweenFlag)[:i], (*betweenFlag)[i+2:]
+	}
+	var err error
+	if after != "" {
+		if w.after, err = parseTimeExpr(after); err != nil {
+			return nil, fmt.Errorf("bad window start: %v", err)
+		}
+	}
+	if before != "" {
+		if w.before, err = parseTimeExpr(before); err != nil {
+			return nil, fmt.Errorf("bad window end: %v", err)
+		}
+	}
+	w.keep = !w.active()
+	return w, nil
+}
+
+func (w *window) active() bool {
+	return !w.after.IsZero() || !w.before.IsZero()
+}
+
+// contains reports whether t is inside the window.
+func (w *window) contains(t time.Time) bool {
+	return (w.after.IsZero() || !t.Before(w.after)) &&
+		(w.before.IsZero() || t.Before(w.before))
+}
+
+// include reports whether a record should be included. If ok is false,
+// the record holds no time and is included only if the most recent
+// record with a time was.
+func (w *window) include(t time.Time, ok bool) bool {
+	if ok {
+		w.keep = w.contains(t)
+		if w.keep && w.seen != nil {
+			w.seen(t)
+		}
+	}
+	return w.keep
+}
+
+// parseTimeExpr parses a time in the any format, interpreted in the
+// -itz time zone, followed by any number of space-separated deltas
+// and truncations, for example "now -2h" or "now trunc:quarter".
+// If there are only deltas and truncations, they're applied to
+// the current time.
+func parseTimeExpr(s string) (time.Time, error) {
+	parseTime, err := timeParser("any", *tzIn)
+	if err != nil {
+		return time.Time{}, err
+	}
+	fields := strings.Fields(s)
+	n := len(fields)
+	for n > 0 {
+		f := fields[n-1]
+		if f[0] == '-' || f[0] == '+' {
+			if _, err := timeparse.ParseDelta(f); err != nil {
+				break
+			}
+		} else if !isTruncation(f) {
+			break
+		}
+		n--
+	}
+	ts := strings.Join(fields[:n], " ")
+	if ts == "" {
+		ts = "now"
+	}
+	t, _, err := parseTime(ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, f := range fields[n:] {
+		if isTruncation(f) {
+			t, err = truncate(t, f)
+			if err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		d, _ := timeparse.ParseDelta(f)
+		t = d.Add(t)
+	}
+	return t, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var newWindowTests = []struct {
+	testName     string
+	after        string
+	before       string
+	between      string
+	expectAfter  time.Time
+	expectBefore time.Time
+	expectError  string
+}{{
+	testName: "none",
+}, {
+	testName:    "after",
+	after:       "2024-03-01 10:00",
+	expectAfter: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+}, {
+	testName:     "before",
+	before:       "2024-03-01T10:00:00Z",
+	expectBefore: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+}, {
+	testName:     "between",
+	between:      "2024-03-01 10:00:00..2024-03-01 10:15:00",
+	expectAfter:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	expectBefore: time.Date(2024, 3, 1, 10, 15, 0, 0, time.UTC),
+}, {
+	testName:     "deltas",
+	after:        "2024-03-01 10:00 -1d +30m",
+	before:       "2024-03-01 10:00 trunc:d +1mo",
+	expectAfter:  time.Date(2024, 2, 29, 10, 30, 0, 0, time.UTC),
+	expectBefore: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	testName:    "between-with-after",
+	after:       "2024-03-01",
+	between:     "2024-03-01..2024-03-02",
+	expectError: `cannot use -between with -after or -before`,
+}, {
+	testName:    "between-without-dots",
+	between:     "2024-03-01",
+	expectError: `-between value "2024-03-01" is not of the form start..end`,
+}, {
+	testName:    "bad-start",
+	after:       "not a time",
+	expectError: `bad window start: cannot parse "not a time" as arbitrary format`,
+}, {
+	testName:    "bad-end",
+	between:     "2024-03-01..+1x",
+	expectError: `bad window end: .*`,
+}}
+
+func TestNewWindow(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(tzIn, "UTC")
+	for _, test := range newWindowTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(afterFlag, test.after)
+			c.Patch(beforeFlag, test.before)
+			c.Patch(betweenFlag, test.between)
+			w, err := newWindow()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(w.after.Equal(test.expectAfter), qt.IsTrue, qt.Commentf("after %v", w.after))
+			c.Assert(w.before.Equal(test.expectBefore), qt.IsTrue, qt.Commentf("before %v", w.before))
+			c.Assert(w.active(), qt.Equals, !test.expectAfter.IsZero() || !test.expectBefore.IsZero())
+		})
+	}
+}
+
+func TestNewWindowRelative(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(tzIn, "UTC")
+	c.Patch(afterFlag, "-2h")
+	t0 := time.Now()
+	w, err := newWindow()
+	c.Assert(err, qt.IsNil)
+	t1 := time.Now()
+	c.Assert(w.after.Before(t0.Add(-2*time.Hour)), qt.IsFalse)
+	c.Assert(w.after.After(t1.Add(-2*time.Hour)), qt.IsFalse)
+	c.Assert(w.before.IsZero(), qt.IsTrue)
+}
+
+func TestWindowInclude(t *testing.T) {
+	c := qt.New(t)
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	w := &window{
+		after:  t0,
+		before: t0.Add(time.Hour),
+	}
+	var seen []time.Time
+	w.seen = func(t time.Time) {
+		seen = append(seen, t)
+	}
+	for i, test := range []struct {
+		t      time.Time
+		ok     bool
+		expect bool
+	}{
+		// Records before the first time are excluded.
+		{ok: false, expect: false},
+		{t: t0.Add(-time.Nanosecond), ok: true, expect: false},
+		{ok: false, expect: false},
+		// The start is inclusive.
+		{t: t0, ok: true, expect: true},
+		{ok: false, expect: true},
+		{ok: false, expect: true},
+		{t: t0.Add(time.Hour - time.Nanosecond), ok: true, expect: true},
+		// The end is exclusive.
+		{t: t0.Add(time.Hour), ok: true, expect: false},
+		{ok: false, expect: false},
+	} {
+		c.Check(w.include(test.t, test.ok), qt.Equals, test.expect, qt.Commentf("record %d", i))
+	}
+	c.Assert(seen, qt.DeepEquals, []time.Time{t0, t0.Add(time.Hour - time.Nanosecond)})
+}
+
+func TestWindowIncludeAll(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(afterFlag, "")
+	c.Patch(beforeFlag, "")
+	c.Patch(betweenFlag, "")
+	w, err := newWindow()
+	c.Assert(err, qt.IsNil)
+	// Records without a time are included
+	// even before the first time.
+	c.Assert(w.include(time.Time{}, false), qt.IsTrue)
+	c.Assert(w.include(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), true), qt.IsTrue)
+}
+
+func TestConvertFileWindow(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(replace, true)
+	c.Patch(orig, true)
+	c.Patch(inFormat, "any")
+	c.Patch(tzIn, "UTC")
+	c.Patch(betweenFlag, "2024-03-01 10:00:00..2024-03-01 10:15:00")
+	w, err := newWindow()
+	c.Assert(err, qt.IsNil)
+	parseTime, err := timeParser(*inFormat, *tzIn)
+	c.Assert(err, qt.IsNil)
+	formatTime, err := formatterFor("rfc3339", "")
+	c.Assert(err, qt.IsNil)
+	input := `
+2024-03-01 09:59:59 before
+	at before()
+2024-03-01 10:00:00 start
+	at start()
+2024-03-01 10:14:59 inside
+2024-03-01 10:15:00 end
+	at end()
+`[1:]
+	var buf bytes.Buffer
+	err = convertFile(strings.NewReader(input), &buf, parseTime, formatTime, w)
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, `
+2024-03-01 10:00:00 start
+	at start()
+2024-03-01 10:14:59 inside
+`[1:])
+}